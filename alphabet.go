@@ -0,0 +1,92 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Encodes n and returns it written in base len(alphabet) using the runes of
+// alphabet as digits. Runes may be multi-byte (eg. emoji). Panics if the
+// alphabet is invalid. See ValidateAlphabet.
+func (this Optimus) EncodeToAlphabet(n uint64, alphabet []rune) string {
+	if err := ValidateAlphabet(alphabet); err != nil {
+		panic(err)
+	}
+	return alphabetEncode(this.Encode(n), alphabet)
+}
+
+// Decodes a string produced by EncodeToAlphabet with the same alphabet.
+func (this Optimus) DecodeFromAlphabet(s string, alphabet []rune) (uint64, error) {
+	if err := ValidateAlphabet(alphabet); err != nil {
+		return 0, err
+	}
+
+	n, err := alphabetDecode(s, alphabet)
+	if err != nil {
+		return 0, err
+	}
+	return this.Decode(n), nil
+}
+
+// Returns an error if alphabet has fewer than 2 runes or contains
+// duplicates.
+func ValidateAlphabet(alphabet []rune) error {
+	if len(alphabet) < 2 {
+		return jsonerror.New(11, "Invalid alphabet", fmt.Sprintf("Alphabet must contain at least 2 runes. Got %d", len(alphabet)))
+	}
+
+	seen := make(map[rune]bool, len(alphabet))
+	for _, r := range alphabet {
+		if seen[r] {
+			return jsonerror.New(11, "Invalid alphabet", fmt.Sprintf("Alphabet contains %q more than once", r))
+		}
+		seen[r] = true
+	}
+	return nil
+}
+
+// Converts n to base len(alphabet).
+func alphabetEncode(n uint64, alphabet []rune) string {
+	base := uint64(len(alphabet))
+	if n == 0 {
+		return string(alphabet[0])
+	}
+
+	var digits []rune
+	for n > 0 {
+		digits = append(digits, alphabet[n%base])
+		n /= base
+	}
+
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits)
+}
+
+// Converts a string produced by alphabetEncode back to a number. s is
+// processed rune by rune so multi-byte runes are supported.
+func alphabetDecode(s string, alphabet []rune) (uint64, error) {
+	if s == "" {
+		return 0, jsonerror.New(4, "Invalid encoded string", "String is empty")
+	}
+
+	values := make(map[rune]uint64, len(alphabet))
+	for i, r := range alphabet {
+		values[r] = uint64(i)
+	}
+	base := uint64(len(alphabet))
+
+	var n uint64
+	for i, r := range s {
+		d, ok := values[r]
+		if !ok {
+			return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("Invalid character %q at position %d", r, i))
+		}
+		if n > (MAX_INT-d)/base {
+			return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q overflows uint64", s))
+		}
+		n = n*base + d
+	}
+	return n, nil
+}
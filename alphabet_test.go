@@ -0,0 +1,57 @@
+package optimus
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Tests that values round-trip through an emoji alphabet.
+func TestEncodeToAlphabetEmoji(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	alphabet := []rune("😀😂🥰😎🤔😴🤯🥳👻🎃🐶🐱🦊🐼🍕🍩")
+
+	for _, value := range []uint64{0, 1, 15, 1103647397, MAX_INT} {
+		s := o.EncodeToAlphabet(value, alphabet)
+		if !utf8.ValidString(s) {
+			t.Errorf("%d encoded to invalid UTF-8 %q", value, s)
+		}
+
+		n, err := o.DecodeFromAlphabet(s, alphabet)
+		if err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, s, n, err)
+		}
+	}
+}
+
+// Tests that a mixed single and multi-byte alphabet round-trips.
+func TestEncodeToAlphabetMixed(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	alphabet := []rune("aé€😀")
+
+	for _, value := range []uint64{0, 3, 4, 255, MAX_INT} {
+		s := o.EncodeToAlphabet(value, alphabet)
+		n, err := o.DecodeFromAlphabet(s, alphabet)
+		if err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, s, n, err)
+		}
+	}
+}
+
+// Tests that invalid alphabets and strings are rejected.
+func TestAlphabetInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, alphabet := range []string{"", "a", "😀😂😀"} {
+		if ValidateAlphabet([]rune(alphabet)) == nil {
+			t.Errorf("Expected alphabet %q to be rejected", alphabet)
+		}
+	}
+
+	alphabet := []rune("😀😂")
+	for _, s := range []string{"", "😀x", "\xf0\x9f\x98", strings.Repeat("😂", 65)} {
+		if _, err := o.DecodeFromAlphabet(s, alphabet); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+}
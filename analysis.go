@@ -0,0 +1,285 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"math/big"
+	"math/bits"
+	"sort"
+)
+
+// Real ids are assumed to fit in this many bits by LikelyEncoded.
+const LIKELY_ID_BITS = 48
+
+// Returns the number of distinct values Encode can produce for o. Encode is
+// a bijection over the 2^64 domain so every uint64 is a possible output and
+// the keyspace is 2^64 regardless of the seed.
+//
+// The prime and random do not change the size of the keyspace, they only
+// determine the order in which it is traversed. Knowing a handful of
+// (id, encoded) pairs is enough to solve for the prime and random, so
+// obfuscated ids must never be relied upon for authorization. A large
+// keyspace only makes it impractical to guess valid ids by enumeration when
+// the real ids are sparse within it.
+func Keyspace(o Optimus) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), 64)
+}
+
+// SeedAnalysis describes properties of a seed's prime relative to the 2^64
+// modulus. See AnalyzeSeed.
+type SeedAnalysis struct {
+	Order           uint64 // Multiplicative order of the prime modulo 2^64, ie. the period of prime^k
+	MaxOrder        uint64 // Largest order any number can have modulo 2^64 (2^62)
+	MaximalOrder    bool   // Whether Order equals MaxOrder
+	PrimitiveRoot   bool   // Whether the prime generates every odd number. Always false since 2^64 has no primitive roots
+	InverseVerified bool   // Whether prime * modInverse is 1 modulo 2^64
+}
+
+// Analyzes the prime of o relative to the 2^64 modulus. This is intended
+// for security reviews.
+//
+// The multiplicative group modulo 2^64 is not cyclic so no prime is a
+// primitive root. The largest possible order is 2^62, which is reached by
+// primes that are 3 or 5 modulo 8.
+func AnalyzeSeed(o Optimus) SeedAnalysis {
+	const maxOrder = 1 << 62
+
+	//The order is a power of 2. Square until 1 is reached.
+	//Even numbers have no multiplicative order.
+	var order uint64
+	if o.prime&1 == 1 {
+		order = 1
+		for x := o.prime; x != 1; x *= x {
+			order <<= 1
+		}
+	}
+
+	return SeedAnalysis{
+		Order:           order,
+		MaxOrder:        maxOrder,
+		MaximalOrder:    order == maxOrder,
+		PrimitiveRoot:   false,
+		InverseVerified: o.prime*o.modInverse == 1,
+	}
+}
+
+// DistributionStats compares how well Encode spreads sequential ids with the
+// multiplication alone. See DistributionReport.
+type DistributionStats struct {
+	Samples      int                 // Number of sequential ids encoded
+	Encoded      DistributionMetrics // Metrics for Encode
+	MultiplyOnly DistributionMetrics // Metrics for n * prime, without the random number
+}
+
+// DistributionMetrics describes a set of outputs produced from the ids 0 to
+// Samples-1.
+type DistributionMetrics struct {
+	BitBalance     [64]float64 // Fraction of outputs with each bit set. Ideally 0.5
+	MaxBitBias     float64     // Largest distance of BitBalance from 0.5
+	MeanHamming    float64     // Mean number of bits differing between the outputs of adjacent ids. Ideally 32
+	MinGap, MaxGap uint64      // Smallest and largest difference between consecutive sorted outputs
+}
+
+// Encodes the ids 0 to sampleCount-1 and reports statistics quantifying how
+// well the seed spreads them, alongside the same statistics for the
+// multiplication alone. This is intended for security reviews. Panics if
+// sampleCount is less than 2.
+func DistributionReport(o Optimus, sampleCount int) DistributionStats {
+	if sampleCount < 2 {
+		panic(jsonerror.New(34, "Invalid count", fmt.Sprintf("sampleCount=%d. Must be at least 2", sampleCount)))
+	}
+
+	encoded := make([]uint64, sampleCount)
+	multiplied := make([]uint64, sampleCount)
+	for i := range encoded {
+		encoded[i] = o.Encode(uint64(i))
+		multiplied[i] = uint64(i) * o.prime
+	}
+
+	return DistributionStats{
+		Samples:      sampleCount,
+		Encoded:      distributionMetrics(encoded),
+		MultiplyOnly: distributionMetrics(multiplied),
+	}
+}
+
+// Computes the DistributionMetrics of outputs, which must hold at least 2
+// values. outputs is sorted in place.
+func distributionMetrics(outputs []uint64) DistributionMetrics {
+	var m DistributionMetrics
+
+	var ones [64]int
+	var hamming int
+	for i, n := range outputs {
+		for bit := range ones {
+			ones[bit] += int(n >> uint(bit) & 1)
+		}
+		if i > 0 {
+			hamming += bits.OnesCount64(n ^ outputs[i-1])
+		}
+	}
+
+	for bit, count := range ones {
+		m.BitBalance[bit] = float64(count) / float64(len(outputs))
+		bias := m.BitBalance[bit] - 0.5
+		if bias < 0 {
+			bias = -bias
+		}
+		if bias > m.MaxBitBias {
+			m.MaxBitBias = bias
+		}
+	}
+	m.MeanHamming = float64(hamming) / float64(len(outputs)-1)
+
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i] < outputs[j] })
+	m.MinGap = MAX_INT
+	for i := 1; i < len(outputs); i++ {
+		gap := outputs[i] - outputs[i-1]
+		if gap < m.MinGap {
+			m.MinGap = gap
+		}
+		if gap > m.MaxGap {
+			m.MaxGap = gap
+		}
+	}
+	return m
+}
+
+// Encodes the ids 0 to sampleSize-1 with both a and b and reports whether
+// the two sets of outputs are disjoint, along with the values produced by
+// both seeds. Each seed is a bijection over the full domain, so outputs
+// always overlap eventually; this only tells you whether the sampled range
+// of real ids can be told apart.
+func DisjointOutputs(a Optimus, b Optimus, sampleSize int) (bool, []uint64) {
+	outputs := make(map[uint64]struct{}, sampleSize)
+	for i := 0; i < sampleSize; i++ {
+		outputs[a.Encode(uint64(i))] = struct{}{}
+	}
+
+	var collisions []uint64
+	for i := 0; i < sampleSize; i++ {
+		encoded := b.Encode(uint64(i))
+		if _, ok := outputs[encoded]; ok {
+			collisions = append(collisions, encoded)
+		}
+	}
+	return len(collisions) == 0, collisions
+}
+
+// Encodes every id and reports whether the encoded values are distinct,
+// along with the first pair of different ids which encode to the same value.
+// A valid seed is a bijection so a collision means the seed is broken, eg.
+// an even prime. Repeated ids are not collisions.
+func AllDistinctEncodings(o Optimus, ids []uint64) (bool, [2]uint64) {
+	seen := make(map[uint64]uint64, len(ids))
+	for _, id := range ids {
+		encoded := o.Encode(id)
+		if other, ok := seen[encoded]; ok && other != id {
+			return false, [2]uint64{other, id}
+		}
+		seen[encoded] = id
+	}
+	return true, [2]uint64{}
+}
+
+// CollisionPair is a pair of different ids which encode to the same value.
+// See ScanCollisions.
+type CollisionPair struct {
+	A       uint64
+	B       uint64
+	Encoded uint64
+}
+
+// Encodes every id and returns every pair of different ids which encode to
+// the same value, eg. as a safety check before re-encoding merged datasets
+// under a new seed. Pairs are ordered by the position of B in ids. A valid
+// seed is a bijection so the result is always empty; a non-empty result
+// means the seed is broken, eg. an even prime which loses the top bit.
+// Repeated ids are not collisions. Returns an error if ids is empty.
+func ScanCollisions(o Optimus, ids []uint64) ([]CollisionPair, error) {
+	if len(ids) == 0 {
+		return nil, jsonerror.New(21, "Empty parameter", "ids is empty")
+	}
+
+	var pairs []CollisionPair
+	seen := make(map[uint64][]uint64, len(ids))
+	for _, id := range ids {
+		encoded := o.Encode(id)
+		others := seen[encoded]
+
+		repeated := false
+		for _, other := range others {
+			if other == id {
+				repeated = true
+				break
+			}
+		}
+		if repeated {
+			continue
+		}
+
+		for _, other := range others {
+			pairs = append(pairs, CollisionPair{A: other, B: id, Encoded: encoded})
+		}
+		seen[encoded] = append(others, id)
+	}
+	return pairs, nil
+}
+
+// Reports whether n looks like it has already been encoded by this seed.
+// This is a heuristic intended for debug assertions which catch
+// double-encoding bugs, not a definitive test.
+//
+// Real ids are usually small, while decoding a value which was not
+// produced by Encode gives a number spread over the whole 64-bit range.
+// n is considered encoded if it decodes to a value which fits in
+// LIKELY_ID_BITS bits. A raw id passes this check with probability of
+// roughly 2^-16.
+func (this Optimus) LikelyEncoded(n uint64) bool {
+	return bits.Len64(this.Decode(n)) <= LIKELY_ID_BITS
+}
+
+// Returns the first of candidates under which both a and b are likely
+// encodings (see LikelyEncoded), eg. to check whether ids from two
+// environments share a seed. This is a heuristic: a wrong seed passes with
+// a probability of roughly 2^-32 per candidate and ids larger than
+// LIKELY_ID_BITS bits are never matched.
+func SameSeedLikely(a uint64, b uint64, candidates []Optimus) (*Optimus, bool) {
+	for _, o := range candidates {
+		if o.LikelyEncoded(a) && o.LikelyEncoded(b) {
+			return &o, true
+		}
+	}
+	return nil, false
+}
+
+// Infers the bit width of the domain an existing corpus of encoded ids was
+// produced in, eg. when inheriting an undocumented system. Returns the
+// smallest supported width (BITS_31, BITS_32 or BITS_64) which fits the
+// largest value. Returns an error if encoded is empty.
+//
+// This is a best-effort inference: encoded values are spread evenly over
+// the domain, so n values from a 32-bit domain all fit in 31 bits with a
+// probability of 2^-n. Use a corpus of at least a few dozen values. Values
+// from a Modular or a LookupTable may come from a narrower domain; use
+// bits.Len64 of the largest value as an upper bound for those.
+func InferBits(encoded []uint64) (uint8, error) {
+	if len(encoded) == 0 {
+		return 0, jsonerror.New(21, "Empty parameter", "Corpus is empty")
+	}
+
+	var max uint64
+	for _, n := range encoded {
+		if n > max {
+			max = n
+		}
+	}
+
+	switch width := bits.Len64(max); {
+	case width <= BITS_31:
+		return BITS_31, nil
+	case width <= BITS_32:
+		return BITS_32, nil
+	}
+	return BITS_64, nil
+}
@@ -0,0 +1,303 @@
+package optimus
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// Tests that the keyspace covers the whole 64-bit domain independently of
+// the seed.
+func TestKeyspace(t *testing.T) {
+	expected := new(big.Int).Add(new(big.Int).SetUint64(MAX_INT), big.NewInt(1))
+
+	for _, o := range []Optimus{NewCalculated(1580030173, 1163945558), NewCalculated(3, 0)} {
+		if k := Keyspace(o); k.Cmp(expected) != 0 {
+			t.Errorf("Expected keyspace %s. Got %s", expected, k)
+		}
+	}
+}
+
+// Tests AnalyzeSeed against primes with known orders modulo 2^64.
+func TestAnalyzeSeed(t *testing.T) {
+	tests := []struct {
+		prime uint64
+		order uint64
+	}{
+		{3, 1 << 62},  //3 mod 8
+		{5, 1 << 62},  //5 mod 8
+		{7, 1 << 61},  //7^2 - 1 = 3 * 2^4
+		{17, 1 << 60}, //17 - 1 = 2^4
+		{257, 1 << 56},
+	}
+
+	for _, test := range tests {
+		a := AnalyzeSeed(NewCalculated(test.prime, 1163945558))
+
+		if a.Order != test.order {
+			t.Errorf("%d: expected order %d. Got %d", test.prime, test.order, a.Order)
+		}
+
+		if a.MaximalOrder != (test.order == 1<<62) || a.MaxOrder != 1<<62 {
+			t.Errorf("%d: unexpected maximal order %t/%d", test.prime, a.MaximalOrder, a.MaxOrder)
+		}
+
+		if a.PrimitiveRoot {
+			t.Errorf("%d: no primitive roots exist modulo 2^64", test.prime)
+		}
+
+		if !a.InverseVerified {
+			t.Errorf("%d: expected inverse to be verified", test.prime)
+		}
+	}
+
+	if AnalyzeSeed(New(1580030173, 59260789, 1163945558)).InverseVerified {
+		t.Errorf("Expected inconsistent modInverse to fail verification")
+	}
+}
+
+// Tests DisjointOutputs with unrelated, identical and contrived overlapping
+// seeds.
+func TestDisjointOutputs(t *testing.T) {
+	a := NewCalculated(1580030173, 1163945558)
+	b := NewCalculated(2123809381, 1198752319)
+
+	if disjoint, collisions := DisjointOutputs(a, b, 10000); !disjoint {
+		t.Errorf("Expected unrelated seeds to be disjoint. Got %v", collisions)
+	}
+
+	if disjoint, collisions := DisjointOutputs(a, a, 100); disjoint || len(collisions) != 100 {
+		t.Errorf("Expected identical seeds to collide on every value. Got %d", len(collisions))
+	}
+
+	//c.Encode(1) == a.Encode(0)
+	c := NewCalculated(1580030173, 1163945558^1580030173)
+	disjoint, collisions := DisjointOutputs(a, c, 10)
+	if disjoint {
+		t.Fatalf("Expected contrived seeds to collide")
+	}
+
+	found := false
+	for _, value := range collisions {
+		if value == a.Encode(0) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected %d in collisions. Got %v", a.Encode(0), collisions)
+	}
+}
+
+// Tests that collisions are only reported for broken seeds.
+func TestAllDistinctEncodings(t *testing.T) {
+	ids := []uint64{0, 1, 2, 15, 15, 1 << 63, MAX_INT}
+
+	if ok, pair := AllDistinctEncodings(NewCalculated(1580030173, 1163945558), ids); !ok {
+		t.Errorf("Expected distinct encodings. Got collision %v", pair)
+	}
+
+	//An even prime loses the top bit so 0 and 2^63 collide. Debug builds
+	//panic on the broken seed instead
+	if debug {
+		return
+	}
+	broken := Optimus{prime: 4, random: 1163945558}
+	if ok, pair := AllDistinctEncodings(broken, ids); ok || pair != [2]uint64{0, 1 << 63} {
+		t.Errorf("Expected 0 and 2^63 to collide. Got %t %v", ok, pair)
+	}
+}
+
+// Tests that ScanCollisions reports nothing for a valid seed and every pair
+// for a broken one.
+func TestScanCollisions(t *testing.T) {
+	ids := []uint64{0, 1, 2, 15, 15, 1 << 63, 1<<63 + 1, MAX_INT}
+
+	if pairs, err := ScanCollisions(NewCalculated(1580030173, 1163945558), ids); err != nil || len(pairs) != 0 {
+		t.Errorf("Expected no collisions. Got %v (%v)", pairs, err)
+	}
+
+	if _, err := ScanCollisions(NewCalculated(1580030173, 1163945558), nil); err == nil {
+		t.Errorf("Expected empty ids to be rejected")
+	}
+
+	//Debug builds panic on the broken seed
+	if debug {
+		return
+	}
+
+	//An even prime loses the top bit so n and n + 2^63 collide. Prime 4 also
+	//loses the second bit so 2^62 and 3 * 2^62 collide with 0
+	broken := Optimus{prime: 4, random: 1163945558}
+	pairs, err := ScanCollisions(broken, append(ids, 1<<62, 3<<62))
+	expected := []CollisionPair{
+		{0, 1 << 63, broken.Encode(0)},
+		{1, 1<<63 + 1, broken.Encode(1)},
+		{0, 1 << 62, broken.Encode(0)},
+		{1 << 63, 1 << 62, broken.Encode(0)},
+		{0, 3 << 62, broken.Encode(0)},
+		{1 << 63, 3 << 62, broken.Encode(0)},
+		{1 << 62, 3 << 62, broken.Encode(0)},
+	}
+	if err != nil || len(pairs) != len(expected) {
+		t.Fatalf("Expected %v. Got %v (%v)", expected, pairs, err)
+	}
+	for i := range expected {
+		if pairs[i] != expected[i] {
+			t.Errorf("%d: Expected %v. Got %v", i, expected[i], pairs[i])
+		}
+	}
+}
+
+// Tests DistributionReport against a small hand-computed sample and checks
+// that a real seed spreads sequential ids evenly.
+func TestDistributionReport(t *testing.T) {
+	//Encodes 0..3 to 1, 2, 7, 8. Multiplying gives 0, 3, 6, 9
+	stats := DistributionReport(NewCalculated(3, 1), 4)
+
+	var balance [64]float64
+	balance[0], balance[1], balance[2], balance[3] = 0.5, 0.5, 0.25, 0.25
+	for name, test := range map[string]struct {
+		metrics        DistributionMetrics
+		minGap, maxGap uint64
+	}{
+		"Encoded":      {stats.Encoded, 1, 5},
+		"MultiplyOnly": {stats.MultiplyOnly, 3, 3},
+	} {
+		m := test.metrics
+		if m.BitBalance != balance || m.MaxBitBias != 0.5 {
+			t.Errorf("%s: Unexpected bit balance %v (max bias %v)", name, m.BitBalance[:4], m.MaxBitBias)
+		}
+		//Adjacent outputs differ in 2, 2 and 4 bits
+		if m.MeanHamming != 8.0/3 {
+			t.Errorf("%s: Expected mean Hamming distance 8/3. Got %v", name, m.MeanHamming)
+		}
+		if m.MinGap != test.minGap || m.MaxGap != test.maxGap {
+			t.Errorf("%s: Expected gaps %d-%d. Got %d-%d", name, test.minGap, test.maxGap, m.MinGap, m.MaxGap)
+		}
+	}
+	if stats.Samples != 4 {
+		t.Errorf("Expected 4 samples. Got %d", stats.Samples)
+	}
+
+	stats = DistributionReport(NewCalculated(5980212987775051159, 1603104512986455411), 10000)
+	if stats.Encoded.MaxBitBias > 0.05 || stats.Encoded.MeanHamming < 28 || stats.Encoded.MeanHamming > 36 {
+		t.Errorf("Expected an even spread. Got max bias %v and mean Hamming distance %v", stats.Encoded.MaxBitBias, stats.Encoded.MeanHamming)
+	}
+
+	//A 31-bit prime times ids below 2^14 never reaches the top bits, which
+	//stay fixed to those of the random number
+	stats = DistributionReport(NewCalculated(1580030173, 1163945558), 10000)
+	if stats.Encoded.MaxBitBias != 0.5 || stats.Encoded.BitBalance[63] != 0 {
+		t.Errorf("Expected the top bits to be fixed. Got %v", stats.Encoded.BitBalance[45:])
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a single sample to panic")
+		}
+	}()
+	DistributionReport(NewCalculated(3, 1), 1)
+}
+
+// Tests that raw sequential ids and encoded ids are told apart with
+// reasonable accuracy.
+func TestLikelyEncoded(t *testing.T) {
+	const samples = 10000
+
+	for _, o := range []Optimus{NewCalculated(1580030173, 1163945558), NewCalculated(9223372036854775783, 1163945558)} {
+		var rawFlagged, encodedMissed int
+		for i := uint64(1); i <= samples; i++ {
+			if o.LikelyEncoded(i) {
+				rawFlagged++
+			}
+			if !o.LikelyEncoded(o.Encode(i)) {
+				encodedMissed++
+			}
+		}
+
+		//Allow 1% error either way
+		if rawFlagged > samples/100 {
+			t.Errorf("Prime %d: %d of %d raw ids were flagged as encoded", o.Prime(), rawFlagged, samples)
+		}
+		if encodedMissed > samples/100 {
+			t.Errorf("Prime %d: %d of %d encoded ids were not flagged", o.Prime(), encodedMissed, samples)
+		}
+	}
+}
+
+// Tests that SameSeedLikely identifies the seed used to encode both ids.
+func TestSameSeedLikely(t *testing.T) {
+	candidates := []Optimus{
+		NewCalculated(1580030173, 1163945558),
+		NewCalculated(2123809381, 1198752319),
+		NewCalculated(9223372036854775783, 1163945558),
+		NewCalculated(1580030173, 9876543210),
+	}
+
+	for i, o := range candidates {
+		a, b := o.Encode(15), o.Encode(123456789)
+		found, ok := SameSeedLikely(a, b, candidates)
+		if !ok || *found != o {
+			t.Errorf("Expected candidate %d. Got %v (%t)", i, found, ok)
+		}
+	}
+
+	//Ids from different seeds
+	if found, ok := SameSeedLikely(candidates[0].Encode(15), candidates[1].Encode(15), candidates); ok {
+		t.Errorf("Expected no seed. Got %v", *found)
+	}
+
+	if _, ok := SameSeedLikely(1, 2, nil); ok {
+		t.Errorf("Expected no seed without candidates")
+	}
+}
+
+// Tests that corpora confined to different bit ranges infer the matching
+// width.
+func TestInferBits(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	corpus := func(width uint) []uint64 {
+		encoded := make([]uint64, 100)
+		for i := range encoded {
+			encoded[i] = r.Uint64() >> (64 - width)
+		}
+		return encoded
+	}
+
+	o32, _ := NewOptimus32Calculated(1580030173, 1163945558, 31)
+	var legacy []uint64
+	for n := uint32(0); n < 100; n++ {
+		legacy = append(legacy, uint64(o32.Encode32(n)))
+	}
+
+	o := NewCalculated(1580030173, 1163945558)
+	var current []uint64
+	for n := uint64(0); n < 100; n++ {
+		current = append(current, o.Encode(n))
+	}
+
+	tests := []struct {
+		encoded []uint64
+		bits    uint8
+	}{
+		{corpus(16), BITS_31},
+		{corpus(31), BITS_31},
+		{legacy, BITS_31},
+		{corpus(32), BITS_32},
+		{corpus(48), BITS_64},
+		{current, BITS_64},
+		{[]uint64{0}, BITS_31},
+		{[]uint64{MAX_INT_32}, BITS_32},
+		{[]uint64{MAX_INT}, BITS_64},
+	}
+
+	for i, test := range tests {
+		if bits, err := InferBits(test.encoded); err != nil || bits != test.bits {
+			t.Errorf("%d: Expected %d bits. Got %d (%v) - FAILED", i, test.bits, bits, err)
+		}
+	}
+
+	if _, err := InferBits(nil); err == nil {
+		t.Errorf("Expected an empty corpus to be rejected")
+	}
+}
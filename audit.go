@@ -0,0 +1,123 @@
+package optimus
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// AuditStatus is the outcome of a single check performed by AuditSeed.
+type AuditStatus uint8
+
+const (
+	AUDIT_PASS AuditStatus = iota
+	AUDIT_WARN
+	AUDIT_FAIL
+)
+
+func (this AuditStatus) String() string {
+	switch this {
+	case AUDIT_PASS:
+		return "pass"
+	case AUDIT_WARN:
+		return "warn"
+	}
+	return "fail"
+}
+
+// AuditCheck is the result of a single check performed by AuditSeed.
+type AuditCheck struct {
+	Name   string
+	Status AuditStatus
+	Detail string
+}
+
+// SeedAudit is the report produced by AuditSeed.
+type SeedAudit struct {
+	Checks   []AuditCheck
+	Score    int      // 0 (unusable) to 100 (no issues found)
+	Keyspace *big.Int // See Keyspace
+}
+
+// Reports whether no check failed. Warnings are allowed.
+func (this SeedAudit) Passed() bool {
+	for _, c := range this.Checks {
+		if c.Status == AUDIT_FAIL {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the checks with the given status.
+func (this SeedAudit) Filter(status AuditStatus) []AuditCheck {
+	var result []AuditCheck
+	for _, c := range this.Checks {
+		if c.Status == status {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Runs every available check against o and returns a single report which
+// can be used as a pre-production gate. Each failed check costs 40 points
+// of the score and each warning 15 points.
+func AuditSeed(o Optimus) SeedAudit {
+	audit := SeedAudit{Keyspace: Keyspace(o)}
+
+	check := func(name string, status AuditStatus, detail string) {
+		audit.Checks = append(audit.Checks, AuditCheck{name, status, detail})
+	}
+
+	if isPrimeStrong(o.prime) {
+		check("primality", AUDIT_PASS, fmt.Sprintf("%d is prime", o.prime))
+	} else {
+		check("primality", AUDIT_FAIL, fmt.Sprintf("%d is not prime", o.prime))
+	}
+
+	if o.prime*o.modInverse == 1 {
+		check("mod_inverse", AUDIT_PASS, "modInverse is consistent with the prime")
+	} else {
+		check("mod_inverse", AUDIT_FAIL, "modInverse is not the inverse of the prime modulo 2^64. Ids will not decode correctly")
+	}
+
+	if o.prime > MAX_INT_32 {
+		check("prime_size", AUDIT_PASS, fmt.Sprintf("prime has %d bits", bits.Len64(o.prime)))
+	} else {
+		check("prime_size", AUDIT_WARN, fmt.Sprintf("prime has only %d bits. Encoded small ids stay small and leak their magnitude", bits.Len64(o.prime)))
+	}
+
+	//If prime = 1 mod 2^k then n * prime = n mod 2^k
+	if tz := bits.TrailingZeros64(o.prime - 1); tz < 8 {
+		check("low_bits", AUDIT_PASS, "low bits of ids are diffused")
+	} else {
+		check("low_bits", AUDIT_WARN, fmt.Sprintf("prime is 1 modulo 2^%d. The low %d bits of ids are only masked by the random number", tz, tz))
+	}
+
+	switch {
+	case o.random == 0:
+		check("random", AUDIT_WARN, "random is 0. Encode(0) is 0 and nothing is masked")
+	case o.random <= MAX_INT_32:
+		check("random", AUDIT_WARN, "random has no high bits set. The high bits of encoded values are not masked")
+	default:
+		check("random", AUDIT_PASS, fmt.Sprintf("random has %d bits", bits.Len64(o.random)))
+	}
+
+	check("keyspace", AUDIT_PASS, fmt.Sprintf("%s possible outputs", audit.Keyspace))
+
+	audit.Score = 100
+	for _, c := range audit.Checks {
+		switch c.Status {
+		case AUDIT_WARN:
+			audit.Score -= 15
+		case AUDIT_FAIL:
+			audit.Score -= 40
+		}
+	}
+	if audit.Score < 0 {
+		audit.Score = 0
+	}
+
+	return audit
+}
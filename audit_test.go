@@ -0,0 +1,62 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that a strong seed passes every check.
+func TestAuditSeedStrong(t *testing.T) {
+	audit := AuditSeed(NewCalculated(9223372036854775783, 0x9E3779B97F4A7C15))
+
+	if !audit.Passed() || audit.Score != 100 {
+		t.Errorf("Expected strong seed to pass with score 100. Got %d", audit.Score)
+	}
+
+	for _, c := range audit.Checks {
+		if c.Status != AUDIT_PASS {
+			t.Errorf("%s: %s - %s", c.Name, c.Status, c.Detail)
+		}
+	}
+
+	if audit.Keyspace.Cmp(Keyspace(Optimus{})) != 0 {
+		t.Errorf("Unexpected keyspace %s", audit.Keyspace)
+	}
+}
+
+// Tests that a contrived weak seed produces multiple warnings.
+func TestAuditSeedWeak(t *testing.T) {
+	//65537 = 2^16 + 1 is small and leaves the low 16 bits unchanged
+	audit := AuditSeed(NewCalculated(65537, 0))
+
+	if !audit.Passed() {
+		t.Errorf("Expected weak seed to pass with warnings")
+	}
+
+	warnings := map[string]bool{}
+	for _, c := range audit.Filter(AUDIT_WARN) {
+		warnings[c.Name] = true
+	}
+	for _, name := range []string{"prime_size", "low_bits", "random"} {
+		if !warnings[name] {
+			t.Errorf("Expected a %s warning", name)
+		}
+	}
+
+	if audit.Score != 100-3*15 {
+		t.Errorf("Expected score %d. Got %d", 100-3*15, audit.Score)
+	}
+}
+
+// Tests that an inconsistent modInverse fails the audit.
+func TestAuditSeedFail(t *testing.T) {
+	audit := AuditSeed(New(1580030173, 59260789, 1163945558))
+
+	if audit.Passed() {
+		t.Errorf("Expected inconsistent seed to fail")
+	}
+
+	failed := audit.Filter(AUDIT_FAIL)
+	if len(failed) != 1 || failed[0].Name != "mod_inverse" {
+		t.Errorf("Expected mod_inverse to fail. Got %v", failed)
+	}
+}
@@ -0,0 +1,88 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"net/url"
+)
+
+const BASE62_ALPHABET = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Encodes n and returns the Base62 representation of the result, eg. for
+// use in URLs.
+func (this Optimus) EncodeToString(n uint64) string {
+	return base62Encode(this.Encode(n))
+}
+
+// Decodes a string produced by EncodeToString. Returns an error if s is not
+// valid Base62.
+func (this Optimus) DecodeFromString(s string) (uint64, error) {
+	n, err := base62Decode(s)
+	if err != nil {
+		return 0, err
+	}
+	return this.Decode(n), nil
+}
+
+// Decodes a string produced by EncodeToString which may have been
+// percent-encoded on its way through a URL, eg. "%41bC". s is unescaped
+// using url.QueryUnescape first. Returns an error if the escaping is
+// invalid or the result is not valid Base62.
+func (this Optimus) DecodeURLEscaped(s string) (uint64, error) {
+	unescaped, err := url.QueryUnescape(s)
+	if err != nil {
+		return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q is not validly escaped: %s", s, err.Error()))
+	}
+	return this.DecodeFromString(unescaped)
+}
+
+// Converts n to its Base62 representation using BASE62_ALPHABET.
+func base62Encode(n uint64) string {
+	if n == 0 {
+		return BASE62_ALPHABET[:1]
+	}
+
+	var buf [11]byte // 62^11 > MAX_INT
+	i := len(buf)
+	for n > 0 {
+		i--
+		buf[i] = BASE62_ALPHABET[n%62]
+		n /= 62
+	}
+	return string(buf[i:])
+}
+
+// Converts a Base62 string produced by base62Encode back to a number.
+// Returns an error if s is empty, contains characters outside
+// BASE62_ALPHABET or does not fit in a uint64.
+func base62Decode(s string) (uint64, error) {
+	if s == "" {
+		return 0, jsonerror.New(4, "Invalid encoded string", "String is empty")
+	}
+
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		d := base62Digit(s[i])
+		if d < 0 {
+			return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("Invalid character %q at position %d", s[i], i))
+		}
+		if n > (MAX_INT-uint64(d))/62 {
+			return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q overflows uint64", s))
+		}
+		n = n*62 + uint64(d)
+	}
+	return n, nil
+}
+
+// Returns the value of a Base62 digit or -1 if c is not in BASE62_ALPHABET.
+func base62Digit(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 10
+	case c >= 'a' && c <= 'z':
+		return int(c-'a') + 36
+	}
+	return -1
+}
@@ -0,0 +1,84 @@
+package optimus
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Tests that Base62 conversion round-trips across the whole uint64 range.
+func TestBase62(t *testing.T) {
+	values := []uint64{0, 1, 61, 62, 3843, 3844, 1163945558, MAX_INT - 1, MAX_INT}
+
+	for _, value := range values {
+		s := base62Encode(value)
+		n, err := base62Decode(s)
+		if err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, s, n, err)
+		}
+	}
+
+	if s := base62Encode(61); s != "z" {
+		t.Errorf("Expected 61 to encode to z. Got %s", s)
+	}
+}
+
+// Tests that malformed Base62 strings are rejected.
+func TestBase62Invalid(t *testing.T) {
+	for _, s := range []string{"", "abc-", " 1", "zzzzzzzzzzzz"} {
+		if _, err := base62Decode(s); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+}
+
+// Tests that EncodeToString round-trips and matches composing Encode with
+// Base62 separately.
+func TestEncodeToString(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint64{0, 1, 15, 1 << 32, MAX_INT} {
+		s := o.EncodeToString(value)
+		if expected := base62Encode(o.Encode(value)); s != expected {
+			t.Errorf("%d: Expected %s. Got %s", value, expected, s)
+		}
+
+		n, err := o.DecodeFromString(s)
+		if err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, s, n, err)
+		}
+	}
+
+	if _, err := o.DecodeFromString("not-base62"); err == nil {
+		t.Errorf("Expected invalid string to be rejected")
+	}
+}
+
+// Tests that percent-encoded and plain strings decode and invalid escaping
+// is rejected.
+func TestDecodeURLEscaped(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint64{0, 15, MAX_INT} {
+		s := o.EncodeToString(value)
+
+		var escaped strings.Builder
+		for i := 0; i < len(s); i++ {
+			fmt.Fprintf(&escaped, "%%%02X", s[i])
+		}
+
+		for _, input := range []string{s, escaped.String(), url.QueryEscape(s)} {
+			n, err := o.DecodeURLEscaped(input)
+			if err != nil || n != value {
+				t.Errorf("%d: %s -> %d (%v) - FAILED", value, input, n, err)
+			}
+		}
+	}
+
+	for _, bad := range []string{"%", "%4", "%zz", "Ab%2", "%2541", "%20", ""} {
+		if _, err := o.DecodeURLEscaped(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package optimus
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Length of the strings produced by EncodeBase64URL: 8 bytes without
+// padding.
+const BASE64URL_LEN = 11
+
+// Encodes n and returns the big-endian bytes of the result in unpadded
+// base64url (RFC 4648), eg. for JWT claims.
+func (this Optimus) EncodeBase64URL(n uint64) string {
+	return base64URLEncode(this.Encode(n))
+}
+
+// Decodes a string produced by EncodeBase64URL. Returns an error if s is
+// padded, not BASE64URL_LEN characters long or not canonical base64url.
+func (this Optimus) DecodeBase64URL(s string) (uint64, error) {
+	n, err := base64URLDecode(s)
+	if err != nil {
+		return 0, err
+	}
+	return this.Decode(n), nil
+}
+
+// Returns the big-endian bytes of n in unpadded base64url.
+func base64URLEncode(n uint64) string {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
+
+// Converts a string produced by base64URLEncode back to a number.
+func base64URLDecode(s string) (uint64, error) {
+	if len(s) != BASE64URL_LEN {
+		return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q must be %d characters long", s, BASE64URL_LEN))
+	}
+
+	b, err := base64.RawURLEncoding.Strict().DecodeString(s)
+	if err != nil {
+		return 0, jsonerror.New(4, "Invalid encoded string", err.Error())
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
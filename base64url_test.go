@@ -0,0 +1,47 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that base64url ids round-trip and are URL safe.
+func TestEncodeBase64URL(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint64{0, 1, 15, 1 << 63, MAX_INT} {
+		s := o.EncodeBase64URL(value)
+		if len(s) != BASE64URL_LEN {
+			t.Errorf("%d: Expected %d characters. Got %s", value, BASE64URL_LEN, s)
+		}
+
+		n, err := o.DecodeBase64URL(s)
+		if err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, s, n, err)
+		}
+	}
+
+	//0 encodes to the random number, which is all ones
+	if s := NewCalculated(1580030173, MAX_INT).EncodeBase64URL(0); s != "__________8" {
+		t.Errorf("Expected __________8. Got %s", s)
+	}
+}
+
+// Tests that malformed input is rejected.
+func TestDecodeBase64URLInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	s := o.EncodeBase64URL(15)
+
+	for _, bad := range []string{
+		"",
+		s[:10],
+		s + "=",
+		s + "A",
+		s[:10] + "+",  //standard alphabet
+		"AAAAAAAAAA/", //standard alphabet
+		"AAAAAAAAAA9", //non-zero trailing bits
+	} {
+		if _, err := o.DecodeBase64URL(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"runtime"
+	"sync"
+)
+
+// Decodes each value in encoded, removing duplicate ids while preserving
+// the order in which they were first seen. Returns an error with the index
+// of the first value which is not a canonical in-domain encoding. See
+// DecodeOK.
+func (this Optimus) DecodeMany(encoded []uint64) ([]uint64, error) {
+	result := make([]uint64, 0, len(encoded))
+	seen := make(map[uint64]struct{}, len(encoded))
+
+	for i, value := range encoded {
+		n, ok := this.DecodeOK(value)
+		if !ok {
+			return nil, jsonerror.New(12, "Out of domain", fmt.Sprintf("index %d: %d is not a canonical encoding", i, value))
+		}
+
+		if _, dup := seen[n]; dup {
+			continue
+		}
+		seen[n] = struct{}{}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// Encodes each value in ns and returns the results in the same order.
+func (this Optimus) EncodeSlice(ns []uint64) []uint64 {
+	result := make([]uint64, len(ns))
+	for i, n := range ns {
+		result[i] = this.Encode(n)
+	}
+	return result
+}
+
+// Encodes each value in ns like EncodeSlice but splits the slice into
+// contiguous chunks encoded concurrently by up to workers goroutines. The
+// results are in the same order as ns. If workers is 0 or negative,
+// runtime.GOMAXPROCS(0) is used. Only worthwhile for very large slices
+// (millions of ids) since Encode is a few nanoseconds.
+func (this Optimus) EncodeSliceParallel(ns []uint64, workers int) []uint64 {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(ns) {
+		workers = len(ns)
+	}
+
+	result := make([]uint64, len(ns))
+	if workers <= 1 {
+		for i, n := range ns {
+			result[i] = this.Encode(n)
+		}
+		return result
+	}
+
+	var wg sync.WaitGroup
+	chunk := (len(ns) + workers - 1) / workers
+	for start := 0; start < len(ns); start += chunk {
+		end := start + chunk
+		if end > len(ns) {
+			end = len(ns)
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				result[i] = this.Encode(ns[i])
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	return result
+}
@@ -0,0 +1,99 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that DecodeMany removes duplicates and preserves first-seen order.
+func TestDecodeMany(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	ids := []uint64{5, 3, 5, 9, 3, 3, 1, 9}
+	expected := []uint64{5, 3, 9, 1}
+
+	var encoded []uint64
+	for _, id := range ids {
+		encoded = append(encoded, o.Encode(id))
+	}
+
+	decoded, err := o.DecodeMany(encoded)
+	if err != nil {
+		t.Fatalf("DecodeMany - FAILED: %v", err)
+	}
+
+	if len(decoded) != len(expected) {
+		t.Fatalf("Expected %v. Got %v", expected, decoded)
+	}
+	for i := range expected {
+		if decoded[i] != expected[i] {
+			t.Errorf("Expected %v. Got %v", expected, decoded)
+			break
+		}
+	}
+
+	if decoded, err := o.DecodeMany(nil); err != nil || len(decoded) != 0 {
+		t.Errorf("Expected empty result. Got %v (%v)", decoded, err)
+	}
+}
+
+// Tests that DecodeMany rejects values which are not canonical encodings.
+func TestDecodeManyOutOfDomain(t *testing.T) {
+	if debug {
+		t.Skip("Debug builds panic on inconsistent seeds")
+	}
+	bad := New(1580030173, 59260789, 1163945558) //modInverse is for 2^31
+
+	if _, err := bad.DecodeMany([]uint64{bad.Encode(15), bad.Encode(15)}); err == nil {
+		t.Errorf("Expected out-of-domain values to be rejected")
+	}
+}
+
+// Tests that EncodeSliceParallel matches EncodeSlice for every worker count.
+func TestEncodeSliceParallel(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	ns := make([]uint64, 1001)
+	for i := range ns {
+		ns[i] = uint64(i) * 7919
+	}
+	expected := o.EncodeSlice(ns)
+
+	for _, workers := range []int{-1, 0, 1, 2, 3, 8, 1000, 5000} {
+		encoded := o.EncodeSliceParallel(ns, workers)
+		if len(encoded) != len(expected) {
+			t.Fatalf("%d workers: Expected %d values. Got %d", workers, len(expected), len(encoded))
+		}
+		for i := range expected {
+			if encoded[i] != expected[i] || o.Decode(encoded[i]) != ns[i] {
+				t.Errorf("%d workers: index %d: Expected %d. Got %d - FAILED", workers, i, expected[i], encoded[i])
+				break
+			}
+		}
+	}
+
+	if encoded := o.EncodeSliceParallel(nil, 4); len(encoded) != 0 {
+		t.Errorf("Expected empty result. Got %v", encoded)
+	}
+}
+
+func benchmarkEncodeSlice(b *testing.B, encode func(ns []uint64) []uint64) {
+	ns := make([]uint64, 1<<20)
+	for i := range ns {
+		ns[i] = uint64(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		encode(ns)
+	}
+}
+
+func BenchmarkEncodeSlice(b *testing.B) {
+	o := NewCalculated(1580030173, 1163945558)
+	benchmarkEncodeSlice(b, o.EncodeSlice)
+}
+
+func BenchmarkEncodeSliceParallel(b *testing.B) {
+	o := NewCalculated(1580030173, 1163945558)
+	benchmarkEncodeSlice(b, func(ns []uint64) []uint64 { return o.EncodeSliceParallel(ns, 0) })
+}
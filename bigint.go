@@ -0,0 +1,58 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"math/big"
+)
+
+// Encodes n like Encode but using big.Int arithmetic throughout. Returns a
+// new big.Int. Returns an error if n is nil, negative or not less than 2^64.
+func (this Optimus) EncodeBig(n *big.Int) (*big.Int, error) {
+	if err := checkBigDomain(n); err != nil {
+		return nil, err
+	}
+
+	mask := new(big.Int).SetUint64(MAX_INT)
+	random := new(big.Int).SetUint64(this.random)
+
+	result := new(big.Int).Mul(n, new(big.Int).SetUint64(this.prime))
+	if this.mode == MODE_ADDITIVE {
+		result.Add(result, random)
+		return result.And(result, mask), nil
+	}
+	result.And(result, mask)
+	return result.Xor(result, random), nil
+}
+
+// Decodes n like Decode but using big.Int arithmetic throughout. Returns a
+// new big.Int. Returns an error if n is nil, negative or not less than 2^64.
+func (this Optimus) DecodeBig(n *big.Int) (*big.Int, error) {
+	if err := checkBigDomain(n); err != nil {
+		return nil, err
+	}
+
+	mask := new(big.Int).SetUint64(MAX_INT)
+	random := new(big.Int).SetUint64(this.random)
+
+	result := new(big.Int)
+	if this.mode == MODE_ADDITIVE {
+		//Add 2^64 so the subtraction can not go negative
+		result.Sub(new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 64)), random)
+	} else {
+		result.Xor(n, random)
+	}
+	result.Mul(result, new(big.Int).SetUint64(this.modInverse))
+	return result.And(result, mask), nil
+}
+
+// Returns an error if n is not inside the 2^64 domain.
+func checkBigDomain(n *big.Int) error {
+	if n == nil {
+		return jsonerror.New(12, "Out of domain", "n is nil")
+	}
+	if n.Sign() < 0 || n.BitLen() > 64 {
+		return jsonerror.New(12, "Out of domain", fmt.Sprintf("n=%s. Must be at least 0 and less than 2^64", n))
+	}
+	return nil
+}
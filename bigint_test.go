@@ -0,0 +1,51 @@
+package optimus
+
+import (
+	"math/big"
+	"testing"
+)
+
+// Tests that EncodeBig and DecodeBig agree with Encode and Decode and
+// round-trip values either side of the domain boundary.
+func TestEncodeBig(t *testing.T) {
+	xor := NewCalculated(1580030173, 1163945558)
+
+	for _, o := range []Optimus{xor, xor.WithMode(MODE_ADDITIVE)} {
+		for _, value := range []uint64{0, 1, 15, 1<<32 - 1, 1 << 32, 1<<63 - 1, 1 << 63, MAX_INT - 1, MAX_INT} {
+			n := new(big.Int).SetUint64(value)
+
+			encoded, err := o.EncodeBig(n)
+			if err != nil {
+				t.Errorf("%d - FAILED: %v", value, err)
+				continue
+			}
+			if !encoded.IsUint64() || encoded.Uint64() != o.Encode(value) {
+				t.Errorf("Mode %d: %d: Expected %d. Got %s", o.Mode(), value, o.Encode(value), encoded)
+			}
+
+			decoded, err := o.DecodeBig(encoded)
+			if err != nil || decoded.Cmp(n) != 0 {
+				t.Errorf("Mode %d: %d: %s -> %s (%v) - FAILED", o.Mode(), value, encoded, decoded, err)
+			}
+
+			if n.Uint64() != value {
+				t.Errorf("Expected argument to be left unchanged")
+			}
+		}
+	}
+}
+
+// Tests that values outside the 2^64 domain are rejected.
+func TestEncodeBigOutOfDomain(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	twoTo64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	for _, n := range []*big.Int{nil, big.NewInt(-1), twoTo64, new(big.Int).Add(twoTo64, big.NewInt(15))} {
+		if _, err := o.EncodeBig(n); err == nil {
+			t.Errorf("Expected EncodeBig(%v) to be rejected", n)
+		}
+		if _, err := o.DecodeBig(n); err == nil {
+			t.Errorf("Expected DecodeBig(%v) to be rejected", n)
+		}
+	}
+}
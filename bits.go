@@ -0,0 +1,47 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Bit widths of the supported domains.
+const (
+	BITS_31 = 31 // Optimus32. Matches the original PHP library
+	BITS_32 = 32 // Optimus32
+	BITS_64 = 64 // Optimus
+
+	DEFAULT_BITS = BITS_64 // Width of the domain used by Optimus (MAX_INT)
+)
+
+// Returns 2^bits, the modulus of a domain of the given width. 2^64 does
+// not fit in a uint64 so ModulusForBits(64) returns 0, which is 2^64
+// modulo 2^64. Use ModulusForBits(bits) - 1 for the largest id, which is
+// MAX_INT for 64 bits. Returns an error if bits is 0 or above 64.
+func ModulusForBits(bits uint8) (uint64, error) {
+	if bits < 1 || bits > BITS_64 {
+		return 0, jsonerror.New(14, "Invalid bit width", fmt.Sprintf("bits=%d. Must be between 1 and 64", bits))
+	}
+	return 1 << bits, nil
+}
+
+// Returns the maximum number of characters an encoded value can take in a
+// domain of the given width (1 to 64 bits) using an alphabet of
+// alphabetSize characters, eg. to size a VARCHAR column. Base62 strings from
+// EncodeToString take at most MaxEncodedStringLen(BITS_64, 62), which is 11.
+// Panics if bits is out of range or alphabetSize is less than 2.
+func MaxEncodedStringLen(bits uint8, alphabetSize int) int {
+	modulus, err := ModulusForBits(bits)
+	if err != nil {
+		panic(err)
+	}
+	if alphabetSize < 2 {
+		panic(jsonerror.New(11, "Invalid alphabet", fmt.Sprintf("alphabetSize=%d. Alphabet must contain at least 2 characters", alphabetSize)))
+	}
+
+	length := 1
+	for max := modulus - 1; max >= uint64(alphabetSize); max /= uint64(alphabetSize) {
+		length++
+	}
+	return length
+}
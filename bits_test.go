@@ -0,0 +1,89 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that ModulusForBits returns 2^bits and rejects invalid widths.
+func TestModulusForBits(t *testing.T) {
+	tests := []struct {
+		bits    uint8
+		modulus uint64
+	}{
+		{1, 2},
+		{16, 65536},
+		{BITS_31, MAX_INT_31 + 1},
+		{BITS_32, MAX_INT_32 + 1},
+		{63, 1 << 63},
+		{BITS_64, 0}, //2^64 wraps to 0
+	}
+
+	for _, test := range tests {
+		modulus, err := ModulusForBits(test.bits)
+		if err != nil || modulus != test.modulus {
+			t.Errorf("%d: Expected %d. Got %d (%v) - FAILED", test.bits, test.modulus, modulus, err)
+		}
+	}
+
+	if modulus, _ := ModulusForBits(DEFAULT_BITS); modulus-1 != MAX_INT {
+		t.Errorf("Expected the largest id of the default domain to be MAX_INT. Got %d", modulus-1)
+	}
+
+	for _, bits := range []uint8{0, 65, 255} {
+		if _, err := ModulusForBits(bits); err == nil {
+			t.Errorf("Expected %d bits to be rejected", bits)
+		}
+	}
+}
+
+// Tests that the computed length matches the longest actual encoding for
+// small domains, and known lengths for the 64-bit domain.
+func TestMaxEncodedStringLen(t *testing.T) {
+	for _, size := range []int{2, 3, 10, 16, 62} {
+		alphabet := []rune(BASE62_ALPHABET[:size])
+		for bits := uint8(1); bits <= 16; bits++ {
+			var longest int
+			for n := uint64(0); n < 1<<bits; n++ {
+				if l := len(alphabetEncode(n, alphabet)); l > longest {
+					longest = l
+				}
+			}
+			if got := MaxEncodedStringLen(bits, size); got != longest {
+				t.Errorf("%d bits, %d characters: Expected %d. Got %d - FAILED", bits, size, longest, got)
+			}
+		}
+	}
+
+	tests := []struct {
+		bits   uint8
+		size   int
+		length int
+	}{
+		{BITS_64, 62, len(base62Encode(MAX_INT))},
+		{BITS_64, 10, NUMERIC_MAX_LEN},
+		{BITS_64, 16, 16},
+		{BITS_64, 2, 64},
+		{BITS_32, 62, 6},
+		{BITS_31, 10, 10},
+	}
+	for _, test := range tests {
+		if got := MaxEncodedStringLen(test.bits, test.size); got != test.length {
+			t.Errorf("%d bits, %d characters: Expected %d. Got %d - FAILED", test.bits, test.size, test.length, got)
+		}
+	}
+
+	for _, f := range []func(){
+		func() { MaxEncodedStringLen(0, 62) },
+		func() { MaxEncodedStringLen(65, 62) },
+		func() { MaxEncodedStringLen(BITS_64, 1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected invalid parameters to panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
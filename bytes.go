@@ -0,0 +1,59 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Encodes n and returns the result as exactly byteLen bytes (1 to 8), in
+// big-endian order if bigEndian is set and little-endian order otherwise.
+// Returns an error if byteLen is out of range or the encoded value does not
+// fit in byteLen bytes.
+// NB: Encoded values are spread over the whole 64-bit range so only a
+// byteLen of 8 fits every value.
+func (this Optimus) EncodeBytesN(n uint64, byteLen int, bigEndian bool) ([]byte, error) {
+	if err := checkByteLen(byteLen); err != nil {
+		return nil, err
+	}
+
+	encoded := this.Encode(n)
+	if byteLen < 8 && encoded>>(8*uint(byteLen)) != 0 {
+		return nil, jsonerror.New(12, "Out of domain", fmt.Sprintf("n=%d encodes to %d which does not fit in %d bytes", n, encoded, byteLen))
+	}
+
+	b := make([]byte, byteLen)
+	for i := range b {
+		shift := 8 * uint(i)
+		if bigEndian {
+			shift = 8 * uint(byteLen-1-i)
+		}
+		b[i] = byte(encoded >> shift)
+	}
+	return b, nil
+}
+
+// Decodes bytes produced by EncodeBytesN with the same byte order. The
+// byteLen is the length of b. Returns an error if b is empty or longer than
+// 8 bytes.
+func (this Optimus) DecodeBytesN(b []byte, bigEndian bool) (uint64, error) {
+	if err := checkByteLen(len(b)); err != nil {
+		return 0, err
+	}
+
+	var encoded uint64
+	for i, c := range b {
+		shift := 8 * uint(i)
+		if bigEndian {
+			shift = 8 * uint(len(b)-1-i)
+		}
+		encoded |= uint64(c) << shift
+	}
+	return this.Decode(encoded), nil
+}
+
+func checkByteLen(byteLen int) error {
+	if byteLen < 1 || byteLen > 8 {
+		return jsonerror.New(14, "Invalid bit width", fmt.Sprintf("byteLen=%d. Must be between 1 and 8", byteLen))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package optimus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// Tests both byte orders against encoding/binary and round-trips for every
+// length which fits.
+func TestEncodeBytesN(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint64{0, 1, 15, 1 << 63, MAX_INT} {
+		big, err := o.EncodeBytesN(value, 8, true)
+		if err != nil || binary.BigEndian.Uint64(big) != o.Encode(value) {
+			t.Errorf("%d: Unexpected big-endian bytes %x (%v)", value, big, err)
+		}
+
+		little, err := o.EncodeBytesN(value, 8, false)
+		if err != nil || binary.LittleEndian.Uint64(little) != o.Encode(value) {
+			t.Errorf("%d: Unexpected little-endian bytes %x (%v)", value, little, err)
+		}
+
+		for _, bigEndian := range []bool{true, false} {
+			b, _ := o.EncodeBytesN(value, 8, bigEndian)
+			n, err := o.DecodeBytesN(b, bigEndian)
+			if err != nil || n != value {
+				t.Errorf("%d: %x -> %d (%v) - FAILED", value, b, n, err)
+			}
+		}
+	}
+
+	//0 encodes to the random number 1163945558 (0x45606656), which needs 4 bytes
+	tests := []struct {
+		byteLen   int
+		bigEndian bool
+		expected  []byte
+	}{
+		{4, true, []byte{0x45, 0x60, 0x66, 0x56}},
+		{4, false, []byte{0x56, 0x66, 0x60, 0x45}},
+		{5, true, []byte{0x00, 0x45, 0x60, 0x66, 0x56}},
+		{6, false, []byte{0x56, 0x66, 0x60, 0x45, 0x00, 0x00}},
+	}
+	for _, test := range tests {
+		b, err := o.EncodeBytesN(0, test.byteLen, test.bigEndian)
+		if err != nil || !bytes.Equal(b, test.expected) {
+			t.Errorf("%d bytes (big-endian: %t): Expected %x. Got %x (%v)", test.byteLen, test.bigEndian, test.expected, b, err)
+			continue
+		}
+
+		n, err := o.DecodeBytesN(b, test.bigEndian)
+		if err != nil || n != 0 {
+			t.Errorf("%x -> %d (%v) - FAILED", b, n, err)
+		}
+	}
+}
+
+// Tests that values which don't fit and invalid lengths are rejected.
+func TestEncodeBytesNInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, byteLen := range []int{1, 2, 3} {
+		if b, err := o.EncodeBytesN(0, byteLen, true); err == nil {
+			t.Errorf("Expected 1163945558 not to fit in %d bytes. Got %x", byteLen, b)
+		}
+	}
+
+	for _, byteLen := range []int{-1, 0, 9} {
+		if _, err := o.EncodeBytesN(0, byteLen, true); err == nil {
+			t.Errorf("Expected a length of %d to be rejected", byteLen)
+		}
+	}
+
+	for _, b := range [][]byte{nil, {}, make([]byte, 9)} {
+		if _, err := o.DecodeBytesN(b, true); err == nil {
+			t.Errorf("Expected %d bytes to be rejected", len(b))
+		}
+	}
+}
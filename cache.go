@@ -0,0 +1,86 @@
+package optimus
+
+import (
+	"container/list"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"sync"
+)
+
+// CachingDecoder wraps an Optimus with a bounded LRU cache mapping
+// encoded values to their decoded ids. It is safe for concurrent use.
+type CachingDecoder struct {
+	optimus Optimus
+	size    int
+
+	mu     sync.Mutex
+	order  *list.List // Front is most recently used
+	items  map[uint64]*list.Element
+	hits   uint64
+	misses uint64
+}
+
+type cacheEntry struct {
+	encoded uint64
+	decoded uint64
+}
+
+// Returns a CachingDecoder which decodes using o and remembers up to size
+// of the most recently decoded values. Panics if size is not positive.
+func NewCachingDecoder(o Optimus, size int) *CachingDecoder {
+	if size <= 0 {
+		panic(jsonerror.New(3, "Invalid cache size", fmt.Sprintf("size=%d. Cache size must be greater than 0", size)))
+	}
+
+	return &CachingDecoder{
+		optimus: o,
+		size:    size,
+		order:   list.New(),
+		items:   make(map[uint64]*list.Element, size),
+	}
+}
+
+// Decodes n using the wrapped Optimus, serving the result from the cache
+// when available. When the cache is full the least recently used entry
+// is evicted.
+func (this *CachingDecoder) Decode(n uint64) uint64 {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if e, ok := this.items[n]; ok {
+		this.hits++
+		this.order.MoveToFront(e)
+		return e.Value.(*cacheEntry).decoded
+	}
+
+	this.misses++
+	decoded := this.optimus.Decode(n)
+
+	if this.order.Len() >= this.size {
+		oldest := this.order.Back()
+		this.order.Remove(oldest)
+		delete(this.items, oldest.Value.(*cacheEntry).encoded)
+	}
+	this.items[n] = this.order.PushFront(&cacheEntry{n, decoded})
+
+	return decoded
+}
+
+// Returns the number of entries currently held in the cache.
+func (this *CachingDecoder) Len() int {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.order.Len()
+}
+
+// Returns the number of cache hits and misses recorded so far.
+func (this *CachingDecoder) Stats() (hits uint64, misses uint64) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.hits, this.misses
+}
+
+// Returns the wrapped Optimus.
+func (this *CachingDecoder) Optimus() Optimus {
+	return this.optimus
+}
@@ -0,0 +1,101 @@
+package optimus
+
+import (
+	"sync"
+	"testing"
+)
+
+// Tests that repeated decodes are served from the cache and that the
+// decoded values match the wrapped Optimus.
+func TestCachingDecoderHitMiss(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	c := NewCachingDecoder(o, 10)
+
+	for i := uint64(0); i < 5; i++ {
+		encoded := o.Encode(i)
+		if got := c.Decode(encoded); got != i {
+			t.Errorf("%d: %d -> %d - FAILED", i, encoded, got)
+		}
+	}
+
+	for i := uint64(0); i < 5; i++ {
+		encoded := o.Encode(i)
+		if got := c.Decode(encoded); got != i {
+			t.Errorf("%d: %d -> %d - FAILED (cached)", i, encoded, got)
+		}
+	}
+
+	hits, misses := c.Stats()
+	if hits != 5 || misses != 5 {
+		t.Errorf("Expected 5 hits and 5 misses. Got %d hits and %d misses", hits, misses)
+	}
+}
+
+// Tests that the least recently used entry is evicted once the cache is full.
+func TestCachingDecoderEviction(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	c := NewCachingDecoder(o, 2)
+
+	a, b, d := o.Encode(1), o.Encode(2), o.Encode(3)
+
+	c.Decode(a)
+	c.Decode(b)
+	c.Decode(a) //a is now the most recently used
+	c.Decode(d) //evicts b
+
+	if c.Len() != 2 {
+		t.Errorf("Expected cache length 2. Got %d", c.Len())
+	}
+
+	c.Decode(a)
+	hits, misses := c.Stats()
+	if hits != 2 || misses != 3 {
+		t.Errorf("Expected 2 hits and 3 misses. Got %d hits and %d misses", hits, misses)
+	}
+
+	c.Decode(b)
+	if _, misses := c.Stats(); misses != 4 {
+		t.Errorf("Expected evicted entry to miss. Got %d misses", misses)
+	}
+}
+
+// Tests that the cache can be used from many goroutines at once.
+// Run with -race.
+func TestCachingDecoderConcurrent(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	c := NewCachingDecoder(o, 16)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := uint64(0); i < 200; i++ {
+				n := (i + uint64(g)) % 32
+				if got := c.Decode(o.Encode(n)); got != n {
+					t.Errorf("%d -> %d - FAILED", n, got)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if c.Len() > 16 {
+		t.Errorf("Cache exceeded its bound: %d entries", c.Len())
+	}
+
+	hits, misses := c.Stats()
+	if hits+misses != 8*200 {
+		t.Errorf("Expected %d lookups. Got %d", 8*200, hits+misses)
+	}
+}
+
+// Tests that a non-positive cache size panics.
+func TestCachingDecoderInvalidSize(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected NewCachingDecoder(o, 0) to panic")
+		}
+	}()
+	NewCachingDecoder(NewCalculated(1580030173, 1163945558), 0)
+}
@@ -0,0 +1,64 @@
+package optimus
+
+import (
+	"github.com/pjebs/jsonerror"
+)
+
+var errNotComposable = jsonerror.New(29, "Not composable", "The composition can not be expressed as a single Optimus. Use a ComposedObfuscator")
+
+// Returns a single Optimus equivalent to encoding with inner and then with
+// outer. This is only possible when the result still has the form
+// (n * p) + r or (n * p) ^ r: inner must be additive or have a random of 0,
+// and an XOR in outer can not follow a non-zero addition from inner.
+// Otherwise an error is returned and a ComposedObfuscator should be used
+// instead.
+// NB: The prime of the result is the product of both primes. It is odd, so
+// it has a modInverse, but it is not prime.
+func Compose(inner Optimus, outer Optimus) (Optimus, error) {
+	//inner(n) = n*p1 + a1 if inner is affine
+	var a1 uint64
+	switch {
+	case inner.random == 0:
+	case inner.mode == MODE_ADDITIVE:
+		a1 = inner.random
+	default:
+		return Optimus{}, errNotComposable
+	}
+
+	o := Optimus{prime: inner.prime * outer.prime, modInverse: inner.modInverse * outer.modInverse}
+	c := a1 * outer.prime
+
+	switch {
+	case outer.mode == MODE_ADDITIVE:
+		o.mode, o.random = MODE_ADDITIVE, c+outer.random
+	case c == 0:
+		o.mode, o.random = MODE_XOR, outer.random
+	case outer.random == 0:
+		o.mode, o.random = MODE_ADDITIVE, c
+	default:
+		return Optimus{}, errNotComposable
+	}
+	return o, nil
+}
+
+// ComposedObfuscator encodes with an inner and then an outer Optimus. It
+// works for every pair of seeds. See Compose.
+type ComposedObfuscator struct {
+	inner Optimus
+	outer Optimus
+}
+
+// Returns a ComposedObfuscator which encodes with inner and then outer.
+func NewComposedObfuscator(inner Optimus, outer Optimus) ComposedObfuscator {
+	return ComposedObfuscator{inner, outer}
+}
+
+// Encodes n with the inner Optimus and then the outer Optimus.
+func (this ComposedObfuscator) Encode(n uint64) uint64 {
+	return this.outer.Encode(this.inner.Encode(n))
+}
+
+// Decodes n with the outer Optimus and then the inner Optimus.
+func (this ComposedObfuscator) Decode(n uint64) uint64 {
+	return this.inner.Decode(this.outer.Decode(n))
+}
@@ -0,0 +1,54 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that Compose is equivalent to applying inner then outer whenever it
+// succeeds, and that ComposedObfuscator always is.
+func TestCompose(t *testing.T) {
+	a := NewCalculated(1580030173, 1163945558)
+	b := NewCalculated(2123809381, 9876543210)
+
+	tests := []struct {
+		inner, outer Optimus
+		composable   bool
+	}{
+		{a.WithMode(MODE_ADDITIVE), b.WithMode(MODE_ADDITIVE), true},
+		{NewCalculated(1580030173, 0), b, true},
+		{NewCalculated(1580030173, 0), b.WithMode(MODE_ADDITIVE), true},
+		{a.WithMode(MODE_ADDITIVE), NewCalculated(2123809381, 0), true},
+		{a, b, false},
+		{a, b.WithMode(MODE_ADDITIVE), false},
+		{a.WithMode(MODE_ADDITIVE), b, false},
+	}
+
+	for i, test := range tests {
+		composed := NewComposedObfuscator(test.inner, test.outer)
+		o, err := Compose(test.inner, test.outer)
+		if (err == nil) != test.composable {
+			t.Errorf("%d: Expected composable=%t. Got %v", i, test.composable, err)
+		}
+
+		for _, value := range []uint64{0, 1, 15, 1 << 63, MAX_INT} {
+			expected := test.outer.Encode(test.inner.Encode(value))
+
+			if got := composed.Encode(value); got != expected {
+				t.Errorf("%d: %d: ComposedObfuscator Expected %d. Got %d", i, value, expected, got)
+			}
+			if got := composed.Decode(expected); got != value {
+				t.Errorf("%d: %d: %d -> %d - FAILED", i, value, expected, got)
+			}
+
+			if err != nil {
+				continue
+			}
+			if got := o.Encode(value); got != expected {
+				t.Errorf("%d: %d: Compose Expected %d. Got %d", i, value, expected, got)
+			}
+			if got := o.Decode(expected); got != value {
+				t.Errorf("%d: %d: %d -> %d - FAILED", i, value, expected, got)
+			}
+		}
+	}
+}
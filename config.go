@@ -0,0 +1,114 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var optimusType = reflect.TypeOf(Optimus{})
+
+// FieldError is an invalid seed found by ValidateConfig.
+type FieldError struct {
+	Path string // Path of the field within the config, eg. Seeds.Users or Tenants[acme]
+	Err  error
+}
+
+// ConfigError is returned by ValidateConfig. It holds every invalid seed in
+// the order they were found.
+type ConfigError []FieldError
+
+func (this ConfigError) Error() string {
+	messages := make([]string, len(this))
+	for i, f := range this {
+		messages[i] = fmt.Sprintf("%s: %s", f.Path, f.Err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validates every Optimus in the config struct cfg (or a pointer to one) in
+// a single pass, eg. at startup before serving. Seeds are found in exported
+// fields through pointers, interfaces, nested structs, slices, arrays and
+// maps. Nil pointers are skipped so that optional seeds can be left unset,
+// but a zero Optimus is invalid. Map entries are visited in key order.
+// Returns a ConfigError naming each invalid field, or an error if cfg is not
+// a struct.
+func ValidateConfig(cfg interface{}) error {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return jsonerror.New(42, "Invalid config", fmt.Sprintf("Expected a struct or a pointer to one. Got %T", cfg))
+	}
+
+	var errs ConfigError
+	validateConfigValue(v, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validates the seeds found in v, appending an error for each invalid one.
+// path is the path of v within the config.
+func validateConfigValue(v reflect.Value, path string, errs *ConfigError) {
+	if v.Type() == optimusType {
+		if err := validateSeed(v.Interface().(Optimus)); err != nil {
+			*errs = append(*errs, FieldError{path, err})
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			validateConfigValue(v.Elem(), path, errs)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := field.Name
+			if path != "" {
+				name = path + "." + name
+			}
+			validateConfigValue(v.Field(i), name, errs)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateConfigValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, key := range keys {
+			validateConfigValue(v.MapIndex(key), fmt.Sprintf("%s[%v]", path, key), errs)
+		}
+	}
+}
+
+// Returns an error if o is unset, its prime is invalid, its modInverse is
+// not the inverse of the prime or it is the identity transform.
+func validateSeed(o Optimus) error {
+	if o == (Optimus{}) {
+		return jsonerror.New(20, "Missing parameter", "Seed is not set")
+	}
+	if err := validatePrime(o.prime); err != nil {
+		return err
+	}
+	if o.prime*o.modInverse != 1 {
+		return jsonerror.New(15, "Invalid modInverse", fmt.Sprintf("%d is not the inverse of %d modulo 2^64", o.modInverse, o.prime))
+	}
+	if isIdentity(o.prime, o.modInverse, o.random) {
+		return ErrIdentityTransform
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that ValidateConfig names every invalid seed in a config holding
+// several.
+func TestValidateConfig(t *testing.T) {
+	type seeds struct {
+		Users  Optimus
+		Orders *Optimus
+	}
+	type config struct {
+		Name     string
+		Seeds    seeds
+		Fallback *Optimus
+		Rotated  []Optimus
+		Tenants  map[string]Optimus
+		Other    interface{}
+		legacy   Optimus
+	}
+
+	valid := NewCalculated(1580030173, 1163945558)
+	notPrime := NewUnchecked(1580030175, ModInverse(1580030173), 1163945558)
+	badInverse := NewUnchecked(1580030173, 59260789, 1163945558)
+
+	cfg := config{
+		Name:     "api",
+		Seeds:    seeds{Users: valid, Orders: &badInverse},
+		Fallback: nil,
+		Rotated:  []Optimus{valid, notPrime},
+		Tenants:  map[string]Optimus{"beta": {}, "acme": valid, "zeta": badInverse},
+		Other:    &notPrime,
+		legacy:   notPrime,
+	}
+
+	err := ValidateConfig(&cfg)
+	errs, ok := err.(ConfigError)
+	if !ok {
+		t.Fatalf("Expected a ConfigError. Got %v", err)
+	}
+
+	expected := []string{"Seeds.Orders", "Rotated[1]", "Tenants[beta]", "Tenants[zeta]", "Other"}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected errors for %v. Got %v", expected, errs)
+	}
+	for i, path := range expected {
+		if errs[i].Path != path || errs[i].Err == nil {
+			t.Errorf("%d: Expected an error for %s. Got %s (%v)", i, path, errs[i].Path, errs[i].Err)
+		}
+	}
+
+	cfg = config{Seeds: seeds{Users: valid}, Rotated: []Optimus{valid}, Tenants: map[string]Optimus{"acme": valid}}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("Expected a valid config. Got %v", err)
+	}
+	if err := ValidateConfig(valid); err != nil {
+		t.Errorf("Expected a valid seed. Got %v", err)
+	}
+}
+
+// Tests that values which are not structs are rejected.
+func TestValidateConfigInvalid(t *testing.T) {
+	var nilConfig *struct{ Seed Optimus }
+	for _, cfg := range []interface{}{nil, 15, "config", []Optimus{}, nilConfig} {
+		if err := ValidateConfig(cfg); err == nil {
+			t.Errorf("Expected %#v to be rejected", cfg)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+//go:build optimus_debug
+// +build optimus_debug
+
+package optimus
+
+import (
+	"fmt"
+)
+
+// Enables domain and seed assertions. Set by building with the optimus_debug tag.
+const debug = true
+
+// Panics if n is larger than max, the largest value in the domain of the
+// type named by what.
+func assertInDomain(what string, n uint64, max uint64) {
+	if n > max {
+		panic(fmt.Sprintf("optimus: %s: %d is outside the domain [0, %d]", what, n, max))
+	}
+}
+
+// Panics with ErrInvalidSeed if modInverse is not the inverse of prime
+// modulo 2^64.
+func assertSeed(prime uint64, modInverse uint64) {
+	if prime*modInverse != 1 {
+		panic(ErrInvalidSeed)
+	}
+}
@@ -0,0 +1,61 @@
+//go:build optimus_debug
+// +build optimus_debug
+
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that out-of-domain inputs panic in debug builds.
+func TestDebugDomainAssertions(t *testing.T) {
+	o32, _ := NewOptimus32Calculated(1580030173, 1163945558, 31)
+	m, _ := NewModular(7919, 123456, 1000003)
+
+	panics := map[string]func(){
+		"Encode32":       func() { o32.Encode32(MAX_INT_31 + 1) },
+		"Decode32":       func() { o32.Decode32(MAX_INT_32) },
+		"Modular.Encode": func() { m.Encode(1000003) },
+		"Modular.Decode": func() { m.Decode(MAX_INT) },
+	}
+
+	for name, f := range panics {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %s to panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+
+	//In-domain inputs do not panic
+	o32.Decode32(o32.Encode32(MAX_INT_31))
+	m.Decode(m.Encode(1000002))
+}
+
+// Tests that Encode and Decode panic with ErrInvalidSeed on an inconsistent
+// seed in debug builds.
+func TestDebugSeedAssertions(t *testing.T) {
+	//59260789 is the inverse of 1580030173 modulo 2^31, not 2^64
+	bad := New(1580030173, 59260789, 1163945558)
+
+	for name, f := range map[string]func(){
+		"Encode": func() { bad.Encode(15) },
+		"Decode": func() { bad.Decode(15) },
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrInvalidSeed {
+					t.Errorf("Expected %s to panic with ErrInvalidSeed. Got %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+
+	//A consistent seed does not panic
+	o := NewCalculated(1580030173, 1163945558)
+	o.Decode(o.Encode(15))
+}
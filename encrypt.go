@@ -0,0 +1,86 @@
+package optimus
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"io"
+)
+
+// Encrypts the prime, modInverse, random and mode of o using AES-GCM so that the
+// seed can be stored at rest. key must be 16, 24 or 32 bytes long. The
+// returned blob contains the nonce followed by the sealed parameters.
+func EncryptSeed(o Optimus, key []byte) ([]byte, error) {
+	gcm, err := seedCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, jsonerror.New(17, "Could not encrypt seed", err.Error())
+	}
+
+	var plaintext [25]byte
+	binary.BigEndian.PutUint64(plaintext[0:], o.prime)
+	binary.BigEndian.PutUint64(plaintext[8:], o.modInverse)
+	binary.BigEndian.PutUint64(plaintext[16:], o.random)
+	plaintext[24] = byte(o.mode)
+
+	return gcm.Seal(nonce, nonce, plaintext[:], nil), nil
+}
+
+// Authenticates and decrypts a blob produced by EncryptSeed. Returns an
+// error if the key is wrong, the blob has been tampered with or the
+// decrypted prime and modInverse are not valid.
+func DecryptSeed(blob []byte, key []byte) (Optimus, error) {
+	gcm, err := seedCipher(key)
+	if err != nil {
+		return Optimus{}, err
+	}
+
+	if len(blob) < gcm.NonceSize() {
+		return Optimus{}, jsonerror.New(18, "Could not decrypt seed", "Blob is too short")
+	}
+
+	nonce, ciphertext := blob[:gcm.NonceSize()], blob[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return Optimus{}, jsonerror.New(18, "Could not decrypt seed", err.Error())
+	}
+
+	if len(plaintext) != 25 {
+		return Optimus{}, jsonerror.New(18, "Could not decrypt seed", fmt.Sprintf("Expected 25 bytes. Got %d", len(plaintext)))
+	}
+
+	prime := binary.BigEndian.Uint64(plaintext[0:])
+	modInverse := binary.BigEndian.Uint64(plaintext[8:])
+	random := binary.BigEndian.Uint64(plaintext[16:])
+	mode := Mode(plaintext[24])
+
+	if err := validatePrime(prime); err != nil {
+		return Optimus{}, err
+	}
+
+	if prime*modInverse != 1 {
+		return Optimus{}, jsonerror.New(18, "Could not decrypt seed", "ModInverse is not consistent with the prime")
+	}
+
+	return Optimus{prime: prime, modInverse: modInverse, random: random, mode: mode}, nil
+}
+
+func seedCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, jsonerror.New(19, "Invalid key", err.Error())
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, jsonerror.New(19, "Invalid key", err.Error())
+	}
+	return gcm, nil
+}
@@ -0,0 +1,75 @@
+package optimus
+
+import (
+	"bytes"
+	"testing"
+)
+
+var testSeedKey = []byte("0123456789abcdef0123456789abcdef")
+
+// Tests that an encrypted seed decrypts to an equal Optimus.
+func TestEncryptSeed(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	blob, err := EncryptSeed(o, testSeedKey)
+	if err != nil {
+		t.Fatalf("EncryptSeed - FAILED: %v", err)
+	}
+
+	if bytes.Contains(blob, []byte("1580030173")) {
+		t.Errorf("Blob must not contain the prime in clear text")
+	}
+
+	decrypted, err := DecryptSeed(blob, testSeedKey)
+	if err != nil || decrypted != o {
+		t.Errorf("Expected %v. Got %v (%v)", o, decrypted, err)
+	}
+
+	again, _ := EncryptSeed(o, testSeedKey)
+	if bytes.Equal(blob, again) {
+		t.Errorf("Expected a fresh nonce for each encryption")
+	}
+}
+
+// Tests that decrypting with the wrong key fails authentication.
+func TestDecryptSeedWrongKey(t *testing.T) {
+	blob, _ := EncryptSeed(NewCalculated(1580030173, 1163945558), testSeedKey)
+
+	if _, err := DecryptSeed(blob, []byte("fedcba9876543210fedcba9876543210")); err == nil {
+		t.Errorf("Expected wrong key to fail")
+	}
+
+	if _, err := DecryptSeed(blob, []byte("short")); err == nil {
+		t.Errorf("Expected invalid key length to fail")
+	}
+}
+
+// Tests that tampered or truncated blobs are rejected.
+func TestDecryptSeedTampered(t *testing.T) {
+	blob, _ := EncryptSeed(NewCalculated(1580030173, 1163945558), testSeedKey)
+
+	for i := range blob {
+		tampered := append([]byte(nil), blob...)
+		tampered[i] ^= 0x01
+		if _, err := DecryptSeed(tampered, testSeedKey); err == nil {
+			t.Errorf("Expected tampering at byte %d to be detected", i)
+		}
+	}
+
+	for _, truncated := range [][]byte{nil, blob[:5], blob[:len(blob)-1]} {
+		if _, err := DecryptSeed(truncated, testSeedKey); err == nil {
+			t.Errorf("Expected truncated blob of %d bytes to be rejected", len(truncated))
+		}
+	}
+}
+
+// Tests that the mode survives encryption.
+func TestEncryptSeedMode(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558).WithMode(MODE_ADDITIVE)
+
+	blob, _ := EncryptSeed(o, testSeedKey)
+	decrypted, err := DecryptSeed(blob, testSeedKey)
+	if err != nil || decrypted != o {
+		t.Errorf("Expected %v. Got %v (%v)", o, decrypted, err)
+	}
+}
@@ -0,0 +1,62 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"os"
+	"strconv"
+)
+
+// Prefix used by GenerateSeedEnv and NewFromEnv when none is given.
+const DEFAULT_ENV_PREFIX = "OPTIMUS"
+
+// Generates a seed locally using GenerateSeedLocal and returns it as
+// environment variable assignments, one per line, which can be appended to
+// a .env file and read back with NewFromEnv:
+//
+//	PREFIX_PRIME=...
+//	PREFIX_MOD_INVERSE=...
+//	PREFIX_RANDOM=...
+//
+// An empty prefix uses DEFAULT_ENV_PREFIX. DO NOT DEVULGE THE OUTPUT!
+func GenerateSeedEnv(prefix string) (string, error) {
+	o, err := GenerateSeedLocal()
+	if err != nil {
+		return "", err
+	}
+	return formatEnv(envPrefix(prefix), *o), nil
+}
+
+// Returns the seed stored in the environment variables PREFIX_PRIME,
+// PREFIX_MOD_INVERSE, PREFIX_RANDOM and optionally PREFIX_MODE, as written
+// by GenerateSeedEnv or FormatSeed. PREFIX_MOD_INVERSE is calculated if it
+// is not set. An empty prefix uses DEFAULT_ENV_PREFIX. The seed is
+// validated.
+func NewFromEnv(prefix string) (Optimus, error) {
+	prefix = envPrefix(prefix)
+
+	fields := make(map[string]uint64)
+	for key, name := range map[string]string{"prime": "_PRIME", "modInverse": "_MOD_INVERSE", "random": "_RANDOM", "mode": "_MODE"} {
+		value, ok := os.LookupEnv(prefix + name)
+		if !ok {
+			if key == "prime" || key == "random" {
+				return Optimus{}, jsonerror.New(20, "Missing parameter", fmt.Sprintf("Environment variable %s is missing", prefix+name))
+			}
+			continue
+		}
+
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return Optimus{}, jsonerror.New(31, "Invalid seed string", fmt.Sprintf("%s=%q is not an unsigned 64-bit integer", prefix+name, value))
+		}
+		fields[key] = n
+	}
+	return seedFromFields(fields)
+}
+
+func envPrefix(prefix string) string {
+	if prefix == "" {
+		return DEFAULT_ENV_PREFIX
+	}
+	return prefix
+}
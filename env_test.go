@@ -0,0 +1,83 @@
+package optimus
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Sets the KEY=VALUE lines of env and returns a function which unsets them.
+func setEnvLines(t *testing.T, env string) func() {
+	var keys []string
+	for _, line := range strings.Split(strings.TrimSpace(env), "\n") {
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			t.Fatalf("Unexpected line %q", line)
+		}
+		os.Setenv(kv[0], kv[1])
+		keys = append(keys, kv[0])
+	}
+	return func() {
+		for _, key := range keys {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+// Tests that the output of GenerateSeedEnv parses back via NewFromEnv.
+func TestGenerateSeedEnv(t *testing.T) {
+	env, err := GenerateSeedEnv("TEST_OPTIMUS")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"TEST_OPTIMUS_PRIME=", "TEST_OPTIMUS_MOD_INVERSE=", "TEST_OPTIMUS_RANDOM="} {
+		if !strings.Contains(env, key) {
+			t.Errorf("Expected %s in %q", key, env)
+		}
+	}
+
+	defer setEnvLines(t, env)()
+
+	o, err := NewFromEnv("TEST_OPTIMUS")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := ParseSeedString(env)
+	if o != expected || o.Prime()*o.ModInverse() != 1 {
+		t.Errorf("Expected %v. Got %v", expected, o)
+	}
+}
+
+// Tests that NewFromEnv reads the mode, calculates a missing modInverse and
+// rejects missing or invalid variables.
+func TestNewFromEnv(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558).WithMode(MODE_ADDITIVE)
+	s, _ := FormatSeed(o, "env")
+
+	unset := setEnvLines(t, s)
+	if got, err := NewFromEnv(""); err != nil || got != o {
+		t.Errorf("Expected %v. Got %v (%v)", o, got, err)
+	}
+
+	os.Unsetenv("OPTIMUS_MOD_INVERSE")
+	if got, err := NewFromEnv(DEFAULT_ENV_PREFIX); err != nil || got != o {
+		t.Errorf("Expected the modInverse to be calculated. Got %v (%v)", got, err)
+	}
+
+	os.Setenv("OPTIMUS_PRIME", "1580030175")
+	if _, err := NewFromEnv(""); err == nil {
+		t.Errorf("Expected a composite prime to be rejected")
+	}
+
+	os.Setenv("OPTIMUS_PRIME", "prime")
+	if _, err := NewFromEnv(""); err == nil {
+		t.Errorf("Expected a non-numeric prime to be rejected")
+	}
+
+	unset()
+	if _, err := NewFromEnv(""); err == nil {
+		t.Errorf("Expected missing variables to be rejected")
+	}
+}
@@ -0,0 +1,36 @@
+package optimus
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// Number of hex characters returned by Fingerprint (64 bits).
+const FINGERPRINT_LEN = 16
+
+// Returns a stable fingerprint of the prime, modInverse and random number:
+// the first 64 bits of their SHA-256 as hex. Log it or compare it across
+// environments to catch a wrong seed being deployed. The mode and version
+// are not included.
+// NB: The fingerprint does not reveal the seed, but it does allow an
+// attacker to confirm a guessed seed. Seeds with small primes and randoms
+// are guessable. See MinSecurePrime.
+func (this Optimus) Fingerprint() string {
+	var b [24]byte
+	binary.BigEndian.PutUint64(b[0:], this.prime)
+	binary.BigEndian.PutUint64(b[8:], this.modInverse)
+	binary.BigEndian.PutUint64(b[16:], this.random)
+
+	h := sha256.New()
+	h.Write([]byte("optimus-go fingerprint\x00"))
+	h.Write(b[:])
+	return hex.EncodeToString(h.Sum(nil))[:FINGERPRINT_LEN]
+}
+
+// Reports whether fp is the Fingerprint of the Optimus. The comparison is
+// done in constant time.
+func (this Optimus) VerifyFingerprint(fp string) bool {
+	return subtle.ConstantTimeCompare([]byte(this.Fingerprint()), []byte(fp)) == 1
+}
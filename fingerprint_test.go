@@ -0,0 +1,41 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that equal seeds share a fingerprint and different seeds don't.
+func TestFingerprint(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	fp := o.Fingerprint()
+
+	if len(fp) != FINGERPRINT_LEN {
+		t.Errorf("Expected %d characters. Got %q", FINGERPRINT_LEN, fp)
+	}
+
+	if same := New(1580030173, ModInverse(1580030173), 1163945558); same.Fingerprint() != fp || !same.VerifyFingerprint(fp) {
+		t.Errorf("Expected equal seeds to share a fingerprint")
+	}
+
+	//The mode and version are not part of the fingerprint
+	if !o.WithMode(MODE_ADDITIVE).WithVersion(2).VerifyFingerprint(fp) {
+		t.Errorf("Expected mode and version to be ignored")
+	}
+
+	others := []Optimus{
+		NewCalculated(1580030173, 1163945559),
+		NewCalculated(2123809381, 1163945558),
+		New(1580030173, 59260789, 1163945558),
+	}
+	for _, other := range others {
+		if other.Fingerprint() == fp || other.VerifyFingerprint(fp) {
+			t.Errorf("Expected %v to have a different fingerprint", other)
+		}
+	}
+
+	for _, bad := range []string{"", fp[:FINGERPRINT_LEN-1], fp + "0"} {
+		if o.VerifyFingerprint(bad) {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
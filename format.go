@@ -0,0 +1,52 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Formats the seed of o so that it can be consumed by other tooling.
+// Supported formats are:
+//
+//	json: {"prime":1580030173,"mod_inverse":...,"random":...}
+//	env:  OPTIMUS_PRIME=... OPTIMUS_MOD_INVERSE=... OPTIMUS_RANDOM=... (one per line)
+//	yaml: prime: ... mod_inverse: ... random: ... (one per line)
+//	go:   optimus.New(1580030173, ..., ...)
+//
+// A mode other than MODE_XOR is included as "mode" (json, yaml),
+// OPTIMUS_MODE (env) or a call to WithMode (go).
+//
+// DO NOT DEVULGE THE OUTPUT!
+func FormatSeed(o Optimus, format string) (string, error) {
+	switch format {
+	case "json":
+		if o.mode != MODE_XOR {
+			return fmt.Sprintf(`{"prime":%d,"mod_inverse":%d,"random":%d,"mode":%d}`, o.prime, o.modInverse, o.random, o.mode), nil
+		}
+		return fmt.Sprintf(`{"prime":%d,"mod_inverse":%d,"random":%d}`, o.prime, o.modInverse, o.random), nil
+	case "env":
+		return formatEnv("OPTIMUS", o), nil
+	case "yaml":
+		s := fmt.Sprintf("prime: %d\nmod_inverse: %d\nrandom: %d\n", o.prime, o.modInverse, o.random)
+		if o.mode != MODE_XOR {
+			s += fmt.Sprintf("mode: %d\n", o.mode)
+		}
+		return s, nil
+	case "go":
+		s := fmt.Sprintf("optimus.New(%d, %d, %d)", o.prime, o.modInverse, o.random)
+		if o.mode == MODE_ADDITIVE {
+			s += ".WithMode(optimus.MODE_ADDITIVE)"
+		}
+		return s, nil
+	}
+	return "", jsonerror.New(22, "Unknown format", fmt.Sprintf("%q is not one of json, env, yaml or go", format))
+}
+
+// Returns the seed as environment variable assignments using prefix.
+func formatEnv(prefix string, o Optimus) string {
+	s := fmt.Sprintf("%s_PRIME=%d\n%s_MOD_INVERSE=%d\n%s_RANDOM=%d\n", prefix, o.prime, prefix, o.modInverse, prefix, o.random)
+	if o.mode != MODE_XOR {
+		s += fmt.Sprintf("%s_MODE=%d\n", prefix, o.mode)
+	}
+	return s
+}
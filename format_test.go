@@ -0,0 +1,110 @@
+package optimus
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Parses "key<sep>value" lines into a map.
+func parseLines(t *testing.T, s string, sep string) map[string]uint64 {
+	m := make(map[string]uint64)
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		parts := strings.SplitN(line, sep, 2)
+		if len(parts) != 2 {
+			t.Fatalf("Malformed line %q", line)
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			t.Fatalf("Malformed value in %q", line)
+		}
+		m[strings.TrimSpace(parts[0])] = n
+	}
+	return m
+}
+
+// Tests that every format produces parseable output containing the seed.
+func TestFormatSeed(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	s, err := FormatSeed(o, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v struct {
+		Prime      json.Number `json:"prime"`
+		ModInverse json.Number `json:"mod_inverse"`
+		Random     json.Number `json:"random"`
+	}
+	d := json.NewDecoder(bytes.NewReader([]byte(s)))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if v.Prime.String() != "1580030173" || v.ModInverse.String() != strconv.FormatUint(o.ModInverse(), 10) || v.Random.String() != "1163945558" {
+		t.Errorf("json: unexpected output %s", s)
+	}
+
+	s, _ = FormatSeed(o, "env")
+	env := parseLines(t, s, "=")
+	if env["OPTIMUS_PRIME"] != o.Prime() || env["OPTIMUS_MOD_INVERSE"] != o.ModInverse() || env["OPTIMUS_RANDOM"] != o.Random() {
+		t.Errorf("env: unexpected output %s", s)
+	}
+
+	s, _ = FormatSeed(o, "yaml")
+	yaml := parseLines(t, s, ":")
+	if yaml["prime"] != o.Prime() || yaml["mod_inverse"] != o.ModInverse() || yaml["random"] != o.Random() {
+		t.Errorf("yaml: unexpected output %s", s)
+	}
+
+	s, _ = FormatSeed(o, "go")
+	expr, err := parser.ParseExpr(s)
+	if err != nil {
+		t.Fatalf("go: %v", err)
+	}
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 3 {
+		t.Fatalf("go: expected a call with 3 arguments. Got %s", s)
+	}
+	for i, expected := range []uint64{o.Prime(), o.ModInverse(), o.Random()} {
+		lit, ok := call.Args[i].(*ast.BasicLit)
+		if !ok || lit.Value != strconv.FormatUint(expected, 10) {
+			t.Errorf("go: argument %d should be %d. Got %s", i, expected, s)
+		}
+	}
+}
+
+// Tests that unknown formats are rejected.
+func TestFormatSeedUnknown(t *testing.T) {
+	if _, err := FormatSeed(NewCalculated(1580030173, 1163945558), "toml"); err == nil {
+		t.Errorf("Expected unknown format to be rejected")
+	}
+}
+
+// Tests that a non-default mode is included in every format.
+func TestFormatSeedMode(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558).WithMode(MODE_ADDITIVE)
+
+	expected := map[string]string{
+		"json": `"mode":1`,
+		"env":  "OPTIMUS_MODE=1",
+		"yaml": "mode: 1",
+		"go":   ".WithMode(optimus.MODE_ADDITIVE)",
+	}
+
+	for format, substring := range expected {
+		s, err := FormatSeed(o, format)
+		if err != nil || !strings.Contains(s, substring) {
+			t.Errorf("%s: expected %q in %q (%v)", format, substring, s, err)
+		}
+	}
+
+	s, _ := FormatSeed(o, "go")
+	if _, err := parser.ParseExpr(s); err != nil {
+		t.Errorf("go: %v", err)
+	}
+}
@@ -1,4 +1,5 @@
-// +build appengine
+//go:build appengine && !optimus_no_network
+// +build appengine,!optimus_no_network
 
 package optimus
 
@@ -0,0 +1,206 @@
+//go:build !optimus_no_network
+// +build !optimus_no_network
+
+package optimus
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Minimum time between downloads from primes.utm.edu so that calling
+// GenerateSeed in a loop does not hammer the site. Defaults to 2 seconds.
+// Set to 0 to disable throttling.
+var NetworkMinInterval = 2 * time.Second
+
+// Throttles downloads across all NetworkPrimeSources.
+var networkLimiter rateLimiter
+
+type rateLimiter struct {
+	mu   sync.Mutex
+	last time.Time // The most recently reserved slot
+}
+
+// Blocks until at least interval has passed since the previous call's
+// slot, or until ctx is done. The slot is reserved under the lock and the
+// wait happens without it, so concurrent callers queue up one interval apart
+// and each of them still honors its own context. A cancelled caller gives
+// its slot back if no later caller has reserved one.
+func (this *rateLimiter) wait(ctx context.Context, interval time.Duration) error {
+	this.mu.Lock()
+	previous := this.last
+	slot := time.Now()
+	if next := previous.Add(interval); !previous.IsZero() && next.After(slot) {
+		slot = next
+	}
+	this.last = slot
+	this.mu.Unlock()
+
+	d := time.Until(slot)
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		this.mu.Lock()
+		if this.last.Equal(slot) {
+			this.last = previous
+		}
+		this.mu.Unlock()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Generates a valid Optimus struct using a randomly selected prime
+// number from this site: http://primes.utm.edu/lists/small/millions/
+// The first 50 million prime numbers are distributed evenly in 50 files.
+// Parameter req should be nil if not using Google App Engine.
+// This Function is Time and CPU intensive. The zip file is streamed rather
+// than buffered to bound memory. Run it once to generate the
+// required seeds.
+// WARNING: Potentially Insecure. Double check that the prime number returned
+// is actually prime number using an independent source.
+// The largest Prime has 9 digits. The smallest has 1 digit. Every prime is
+// therefore below the default MinSecurePrime and the seed is flagged as
+// weak. Use GenerateSeedLocal for production seeds.
+// The final return value is the website zip file identifier that was used to obtain the prime number
+func GenerateSeed(req *http.Request) (*Optimus, error, uint8) {
+	src := &NetworkPrimeSource{Request: req}
+	o, err := GenerateSeedFrom(src)
+	return o, err, src.File
+}
+
+// Generates a seed like GenerateSeed but always downloads the file
+// identified by fileIndex (1-50) instead of a random one. This is intended
+// for debugging since the prime is still picked from a random position.
+// Returns an error if fileIndex is out of range. Like GenerateSeed, the prime
+// is always below the default MinSecurePrime.
+func GenerateSeedFromFile(req *http.Request, fileIndex uint8) (*Optimus, error) {
+	if fileIndex < 1 || fileIndex > 50 {
+		return nil, jsonerror.New(25, "Invalid file index", fmt.Sprintf("fileIndex=%d. Must be between 1 and 50", fileIndex))
+	}
+	return GenerateSeedFrom(&NetworkPrimeSource{Request: req, FixedFile: fileIndex})
+}
+
+// Downloads a randomly selected zip file like GenerateSeed and returns up to
+// count validated primes found around a random position within it. Use it
+// to pick the prime yourself and build the Optimus with NewCalculated.
+// The window is small so fewer than count primes are usually returned.
+// Every candidate is below the default MinSecurePrime.
+// Parameter req should be nil if not using Google App Engine.
+func GenerateCandidatePrimes(req *http.Request, count int) ([]uint64, error) {
+	return CandidatePrimesFrom(&NetworkPrimeSource{Request: req}, count)
+}
+
+// Generates a seed using GenerateSeed and then independently verifies the
+// prime using trial division and deterministic Miller-Rabin witnesses,
+// retrying if the check fails. The returned prime does not need to be
+// verified manually, but like GenerateSeed it is always below the default
+// MinSecurePrime.
+func GenerateValidatedSeed() (*Optimus, error) {
+	return generateValidatedSeed(&NetworkPrimeSource{})
+}
+
+// NetworkPrimeSource obtains candidate primes by downloading one of the 50
+// zip files from http://primes.utm.edu/lists/small/millions/ and reading a
+// small window of numbers at a random position.
+// WARNING: Potentially Insecure. See GenerateSeed.
+type NetworkPrimeSource struct {
+	Request   *http.Request   // Should be nil if not using Google App Engine
+	BaseURL   string          // URL pattern taking the file identifier. Defaults to primes.utm.edu
+	Trace     func(TraceInfo) // Optional. Called with the details of each generation
+	Context   context.Context // Optional. Defaults to the Request's context
+	FixedFile uint8           // Optional. The zip file identifier (1-50) to always use. 0 picks one at random
+	File      uint8           // The zip file identifier used by the last call to Primes
+
+	trace TraceInfo
+}
+
+// Downloads a randomly selected zip file and returns the numbers found
+// around a random position within it. Failed or malformed downloads return
+// error code 43 rather than code 1, so that they can be told apart from
+// local failures.
+func (this *NetworkPrimeSource) Primes() ([]uint64, error) {
+	log.Printf("\x1b[31mWARNING: Optimus generates a random number via this site: http://primes.utm.edu/lists/small/millions/. This is potentially insecure!\x1b[39;49m")
+
+	ctx := this.Context
+	if ctx == nil && this.Request != nil {
+		ctx = this.Request.Context()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := networkLimiter.wait(ctx, NetworkMinInterval); err != nil {
+		return nil, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+
+	baseURL := this.BaseURL
+	if baseURL == "" {
+		baseURL = "http://primes.utm.edu/lists/small/millions/primes%d.zip"
+	}
+
+	//Generate Random number between 1-50
+	b_49 := *big.NewInt(49)
+	n, err := rand.Int(randReader, &b_49)
+	if err != nil {
+		return nil, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+	i_n := n.Uint64() + 1
+	if this.FixedFile != 0 {
+		i_n = uint64(this.FixedFile)
+	}
+	this.File = uint8(i_n)
+
+	//Download zip file
+	finalUrl := fmt.Sprintf(baseURL, i_n)
+	this.trace = TraceInfo{File: this.File, URL: finalUrl}
+	log.Printf("Using file: %s", finalUrl)
+
+	httpReq, err := http.NewRequest("GET", finalUrl, nil)
+	if err != nil {
+		return nil, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+
+	resp, err := client(this.Request).Do(httpReq.WithContext(ctx))
+	if err != nil {
+		return nil, jsonerror.New(43, "Could not download primes", err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, jsonerror.New(43, "Could not download primes", fmt.Sprintf("%s returned %s", finalUrl, resp.Status))
+	}
+
+	selectedNumbers, min, max, err := readPrimesWindow(resp.Body)
+	if err != nil {
+		return nil, jsonerror.New(43, "Could not download primes", err.Error())
+	}
+
+	this.trace.WindowStart = min
+	this.trace.WindowEnd = max
+	this.trace.Candidates = selectedNumbers
+
+	return selectedNumbers, nil
+}
+
+// Calls Trace (if set) with the details recorded by the last call to Primes.
+func (this *NetworkPrimeSource) selected(prime uint64) {
+	if this.Trace != nil {
+		this.trace.Prime = prime
+		this.Trace(this.trace)
+	}
+}
@@ -0,0 +1,476 @@
+//go:build !appengine && !optimus_no_network
+// +build !appengine,!optimus_no_network
+
+package optimus
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/ioutil"
+	// "log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func init() {
+	//Tests which need throttling set NetworkMinInterval themselves
+	NetworkMinInterval = 0
+}
+
+// Starts a server which serves the zip returned by files for each requested
+// file identifier. The returned URL pattern can be used as a BaseURL.
+func fakePrimesServer(t *testing.T, files func(file int) []byte) (*httptest.Server, string) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var file int
+		if _, err := fmt.Sscanf(r.URL.Path, "/primes%d.zip", &file); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		body := files(file)
+		w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+		w.Write(body)
+	}))
+	return srv, srv.URL + "/primes%d.zip"
+}
+
+// Obtains a prime number from the internet, calculates the mod inverse of it and
+// calculates a random number. It then checks if the process worked BUT does not
+// test if the number obtained is actually Prime.
+func TestGenerateSeed(t *testing.T) {
+
+	for i := 0; i < 3; i++ { //How many times we want to run GenerateSeed()
+		o, err, f := GenerateSeed(nil)
+		if err != nil {
+			t.Errorf("Try %d - Failed", i)
+		}
+
+		//Check if prime is contained in zipped text file
+		baseURL := "http://primes.utm.edu/lists/small/millions/primes%d.zip"
+		finalUrl := fmt.Sprintf(baseURL, f)
+
+		resp, err := client(nil).Get(finalUrl)
+		if err != nil {
+			t.Errorf("Try %d - Failed", i)
+			continue
+		}
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Errorf("Try %d - Failed", i)
+			continue
+		}
+
+		r, err := zip.NewReader(bytes.NewReader(body), resp.ContentLength)
+		if err != nil {
+			t.Errorf("Try %d - Failed", i)
+			continue
+		}
+
+		zippedFile := r.File[0]
+
+		src, err := zippedFile.Open() //src contains ReaderCloser
+		if err != nil {
+			t.Errorf("Try %d - Failed", i)
+			continue
+		}
+		// defer src.Close()
+
+		//Create a Byte Slice
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(src)
+		b := buf.Bytes()
+		stringContents := *(*string)(unsafe.Pointer(&b))
+
+		subString := fmt.Sprintf(" %d ", o.Prime())
+		if !strings.Contains(stringContents, subString) {
+			src.Close()
+			t.Errorf("Try %d - Failed - Obtained Prime:%d is not a Prime", i, o.Prime())
+			continue
+		}
+
+		src.Close()
+
+		//Check if ModInverse is correct
+
+		// if o.Prime() != ModInverse(o.ModInverse()) {
+		// 	src.Close()
+		// 	t.Errorf("Try %d - Failed - ModInverse(%d) of %d is not correct", i, o.ModInverse, o.Prime)
+		// 	continue
+		// }
+
+	}
+}
+
+// Tests if the encoding process correctly decodes the id back to the original.
+func TestEncoding(t *testing.T) {
+	for i := 0; i < 15; i++ { //How many times we want to run GenerateSeed()
+		o, _, _ := GenerateSeed(nil)
+
+		c := 10
+		h := 100 //How many random numbers to select in between 0-c and (MAX_INT-c) - MAX-INT
+
+		var y []uint64 //Stores all the values we want to run encoding tests on
+
+		for t := 0; t < c; t++ {
+			y = append(y, uint64(t))
+		}
+
+		//Generate Random numbers
+		for t := 0; t < h; t++ {
+			upper := *new(big.Int).SetUint64(MAX_INT - 2*uint64(c))
+			rand, _ := rand.Int(rand.Reader, &upper)
+			randomNumber := rand.Uint64() + uint64(c)
+
+			y = append(y, randomNumber)
+		}
+
+		for t := uint64(MAX_INT); t >= MAX_INT-uint64(c); t-- {
+			y = append(y, uint64(t))
+		}
+
+		t.Logf("Prime: %d ModInverse: %d Random: %d", o.Prime(), o.ModInverse(), o.Random())
+		for _, value := range y {
+			orig := value
+			hashed := o.Encode(value)
+			unhashed := o.Decode(hashed)
+
+			if orig != unhashed {
+				t.Errorf("%d: %d -> %d - FAILED", orig, hashed, unhashed)
+			} else {
+				t.Logf("%d: %d -> %d - PASSED", orig, hashed, unhashed)
+				// log.Printf("%d: %d -> %d - PASSED", orig, hashed, unhashed)
+			}
+		}
+
+	}
+}
+
+// Tests that the Trace callback reports the details of the stubbed download.
+func TestNetworkPrimeSourceTrace(t *testing.T) {
+	contents := fakePrimesHeader + strings.Repeat("       7", 200) //Single digits can not be split by the window
+	if len(fakePrimesHeader) != 67 {
+		t.Fatalf("Header must be 67 characters. Got %d", len(fakePrimesHeader))
+	}
+
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte { return fakePrimesZip(t, contents) })
+	defer srv.Close()
+
+	var traces []TraceInfo
+	src := &NetworkPrimeSource{BaseURL: baseURL, Trace: func(info TraceInfo) {
+		traces = append(traces, info)
+	}}
+
+	o, err := GenerateSeedFrom(src)
+	if err != nil {
+		t.Fatalf("GenerateSeedFrom - FAILED: %v", err)
+	}
+
+	if len(traces) != 1 {
+		t.Fatalf("Expected 1 trace. Got %d", len(traces))
+	}
+	info := traces[0]
+
+	if info.File < 1 || info.File > 50 || info.File != src.File {
+		t.Errorf("Unexpected file identifier %d", info.File)
+	}
+
+	if info.URL != fmt.Sprintf(baseURL, info.File) {
+		t.Errorf("Unexpected URL %s", info.URL)
+	}
+
+	if info.WindowStart < 67 || info.WindowEnd > uint64(len(contents)) || info.WindowStart >= info.WindowEnd {
+		t.Errorf("Unexpected window [%d, %d)", info.WindowStart, info.WindowEnd)
+	}
+
+	if len(info.Candidates) == 0 {
+		t.Errorf("Expected candidates to be recorded")
+	}
+	for _, c := range info.Candidates {
+		if c != 7 {
+			t.Errorf("Unexpected candidate %d", c)
+		}
+	}
+
+	if info.Prime != 7 || o.Prime() != 7 {
+		t.Errorf("Expected selected prime 7. Got %d (Optimus: %d)", info.Prime, o.Prime())
+	}
+}
+
+// Tests that a failing randReader returns an error instead of panicking,
+// both when picking the file and when picking the position within it.
+func TestNetworkPrimeSourceFailingRand(t *testing.T) {
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte {
+		return fakePrimesZip(t, fakePrimesHeader+strings.Repeat("1000003\n", 1000))
+	})
+	defer srv.Close()
+
+	defer func(original io.Reader) { randReader = original }(randReader)
+
+	//The file pick consumes a single byte so the position pick fails
+	for _, b := range [][]byte{nil, {0}} {
+		randReader = bytes.NewReader(b)
+		if _, err := (&NetworkPrimeSource{BaseURL: baseURL}).Primes(); err == nil {
+			t.Errorf("%d random bytes: Expected an error - FAILED", len(b))
+		}
+	}
+}
+
+// Tests that two rapid downloads are spaced by at least NetworkMinInterval.
+func TestNetworkRateLimit(t *testing.T) {
+	defer func(interval time.Duration) { NetworkMinInterval = interval }(NetworkMinInterval)
+	NetworkMinInterval = 100 * time.Millisecond
+
+	var mu sync.Mutex
+	var requests int
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		return fakePrimesZip(t, fakePrimesHeader+strings.Repeat("       7", 200))
+	})
+	defer srv.Close()
+
+	//Forget earlier downloads so only the second call is throttled
+	networkLimiter.mu.Lock()
+	networkLimiter.last = time.Time{}
+	networkLimiter.mu.Unlock()
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := GenerateSeedFrom(&NetworkPrimeSource{BaseURL: baseURL}); err != nil {
+			t.Fatalf("Try %d - Failed: %v", i, err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	if requests != 2 {
+		t.Fatalf("Expected 2 requests. Got %d", requests)
+	}
+
+	if elapsed < NetworkMinInterval {
+		t.Errorf("Expected 2 downloads to take at least %s. Took %s", NetworkMinInterval, elapsed)
+	}
+}
+
+// Tests that waiting for the rate limiter honors context cancellation.
+func TestNetworkRateLimitCancel(t *testing.T) {
+	defer func(interval time.Duration) { NetworkMinInterval = interval }(NetworkMinInterval)
+	NetworkMinInterval = time.Hour
+
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte {
+		return fakePrimesZip(t, fakePrimesHeader+strings.Repeat("       7", 200))
+	})
+	defer srv.Close()
+
+	//Make sure a download has happened recently
+	networkLimiter.wait(context.Background(), 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := GenerateSeedFrom(&NetworkPrimeSource{BaseURL: baseURL, Context: ctx}); err == nil {
+		t.Errorf("Expected cancelled context to abort the download")
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected cancellation to return promptly. Took %s", elapsed)
+	}
+}
+
+// Tests that a caller queued behind another waiting caller still honors
+// its own context instead of blocking until the first caller is done.
+func TestRateLimiterTwoWaitersCancel(t *testing.T) {
+	var limiter rateLimiter
+	limiter.wait(context.Background(), 0)
+
+	//The first waiter holds the next slot for an hour
+	first, cancelFirst := context.WithCancel(context.Background())
+	defer cancelFirst()
+	firstDone := make(chan error, 1)
+	go func() { firstDone <- limiter.wait(first, time.Hour) }()
+
+	time.Sleep(20 * time.Millisecond)
+
+	second, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := limiter.wait(second, time.Hour); err == nil {
+		t.Errorf("Expected the second waiter to be cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the second waiter to return promptly. Took %s", elapsed)
+	}
+
+	cancelFirst()
+	select {
+	case err := <-firstDone:
+		if err == nil {
+			t.Errorf("Expected the first waiter to be cancelled")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the first waiter to return promptly")
+	}
+}
+
+// Tests that concurrent waiters are spaced by the interval.
+func TestRateLimiterSpacing(t *testing.T) {
+	var limiter rateLimiter
+	const interval = 30 * time.Millisecond
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.wait(context.Background(), interval)
+		}()
+	}
+	wg.Wait()
+
+	//The first waiter passes straight away
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("Expected 3 waiters to take at least %s. Took %s", 2*interval, elapsed)
+	}
+}
+
+// Tests that failed downloads are reported as such and map to 502.
+func TestNetworkPrimeSourceDownloadError(t *testing.T) {
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte { return []byte("not a zip") })
+	closed, closedURL := fakePrimesServer(t, nil)
+	closed.Close()
+	defer srv.Close()
+
+	for _, url := range []string{baseURL, closedURL, srv.URL + "/missing%d"} {
+		_, err := GenerateSeedFrom(&NetworkPrimeSource{BaseURL: url})
+		if status := HTTPStatus(err); status != http.StatusBadGateway {
+			t.Errorf("%s: Expected %d. Got %d (%v) - FAILED", url, http.StatusBadGateway, status, err)
+		}
+	}
+}
+
+// Tests that a large zip file is streamed rather than buffered and that the
+// primes are still extracted correctly.
+func TestNetworkPrimeSourceStreaming(t *testing.T) {
+	const size = 8 << 20
+	contents := fakePrimesHeader + strings.Repeat("1000003\n", (size-len(fakePrimesHeader))/8)
+	body := fakePrimesZipSized(t, contents)
+
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte { return body })
+	defer srv.Close()
+
+	src := &NetworkPrimeSource{BaseURL: baseURL}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	primes, err := src.Primes()
+
+	runtime.ReadMemStats(&after)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, p := range primes {
+		found = found || p == 1000003
+	}
+	if !found {
+		t.Errorf("Expected 1000003 in %v", primes)
+	}
+
+	//Buffering would allocate at least the compressed and uncompressed sizes
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > size/8 {
+		t.Errorf("Allocated %d bytes for a %d byte file", allocated, size)
+	}
+}
+
+// Tests that zip files which only record sizes after the data still work.
+func TestNetworkPrimeSourceDataDescriptor(t *testing.T) {
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte {
+		return fakePrimesZip(t, fakePrimesHeader+strings.Repeat("1000003\n", 1000))
+	})
+	defer srv.Close()
+
+	primes, err := (&NetworkPrimeSource{BaseURL: baseURL}).Primes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(primes) == 0 {
+		t.Errorf("Expected candidates")
+	}
+}
+
+// Tests that FixedFile always downloads the requested file.
+func TestNetworkPrimeSourceFixedFile(t *testing.T) {
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte {
+		if file != 17 {
+			return nil
+		}
+		return fakePrimesZip(t, fakePrimesHeader+strings.Repeat("       7", 200))
+	})
+	defer srv.Close()
+
+	src := &NetworkPrimeSource{BaseURL: baseURL, FixedFile: 17}
+	for i := 0; i < 5; i++ {
+		o, err := GenerateSeedFrom(src)
+		if err != nil {
+			t.Fatalf("Try %d - FAILED: %v", i, err)
+		}
+		if src.File != 17 || o.Prime() != 7 {
+			t.Errorf("Expected file 17 and prime 7. Got file %d and prime %d", src.File, o.Prime())
+		}
+	}
+}
+
+// Tests that GenerateSeedFromFile rejects file indexes outside 1-50.
+func TestGenerateSeedFromFileInvalid(t *testing.T) {
+	for _, fileIndex := range []uint8{0, 51, 255} {
+		if _, err := GenerateSeedFromFile(nil, fileIndex); err == nil {
+			t.Errorf("Expected file index %d to be rejected", fileIndex)
+		}
+	}
+}
+
+// Tests that GenerateCandidatePrimes only returns primes from the window.
+// 1000001 is composite and no prefix or suffix of the primes is prime, so
+// numbers split by the window edges are rejected.
+func TestGenerateCandidatePrimes(t *testing.T) {
+	srv, baseURL := fakePrimesServer(t, func(file int) []byte {
+		return fakePrimesZip(t, fakePrimesHeader+strings.Repeat("  1000081  1000099  1000001  1000121", 100))
+	})
+	defer srv.Close()
+
+	expected := map[uint64]bool{1000081: true, 1000099: true, 1000121: true}
+	for i := 0; i < 20; i++ {
+		primes, err := CandidatePrimesFrom(&NetworkPrimeSource{BaseURL: baseURL}, 5)
+		if err != nil {
+			//The window may only contain 1000001 and fragments
+			continue
+		}
+		if len(primes) > 5 {
+			t.Errorf("Expected at most 5 candidates. Got %v", primes)
+		}
+		for _, p := range primes {
+			if !expected[p] {
+				t.Errorf("Try %d - FAILED - Unexpected candidate %d", i, p)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package optimus
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"net/http"
+	"strings"
+)
+
+// HTTP status codes returned by HTTPStatus for each error code. Malformed,
+// tampered or out-of-domain ids are the client's fault.
+var httpStatuses = map[int]int{
+	4:  http.StatusBadRequest, // Invalid encoded string
+	5:  http.StatusBadRequest, // Unknown version
+	7:  http.StatusBadRequest, // Reserved value
+	12: http.StatusBadRequest, // Out of domain
+	13: http.StatusBadRequest, // Invalid number
+	20: http.StatusBadRequest, // Missing parameter
+	21: http.StatusBadRequest, // Empty parameter
+	26: http.StatusBadRequest, // ErrSignatureInvalid
+	27: http.StatusBadRequest, // Invalid slug format
+	28: http.StatusBadRequest, // ErrZeroID
+	32: http.StatusBadRequest, // Tag mismatch
+	36: http.StatusNotFound,   // Unknown tenant
+	43: http.StatusBadGateway, // Could not download primes
+}
+
+// Returns the HTTP status code a web service should respond with for an
+// error returned (or recovered by Try) from this package. Errors caused by
+// the request, such as malformed, tampered or out-of-domain ids and missing
+// parameters, map to 400. An unknown tenant maps to 404 and a failed
+// download of the primes to 502. Returns 200 for nil and 500 for every other
+// error, including local seed generation failures and errors from other
+// packages.
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var je jsonerror.JE
+	if !errors.As(err, &je) {
+		var recovered RecoveredError
+		if !errors.As(err, &recovered) {
+			return http.StatusInternalServerError
+		}
+		je = recovered.JE
+	}
+
+	if status, ok := httpStatuses[je.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Reads the query parameter key from r, Base62-decodes it and decodes the
+// result using o. Returns an error if the parameter is missing, empty or
+// not valid Base62.
+func DecodeQueryParam(o Optimus, r *http.Request, key string) (uint64, error) {
+	values, ok := r.URL.Query()[key]
+	if !ok {
+		return 0, jsonerror.New(20, "Missing parameter", fmt.Sprintf("Query parameter %q is missing", key))
+	}
+
+	if values[0] == "" {
+		return 0, jsonerror.New(21, "Empty parameter", fmt.Sprintf("Query parameter %q is empty", key))
+	}
+
+	return o.DecodeFromString(values[0])
+}
+
+// Base62-decodes a path or query parameter which has already been extracted
+// by a router and decodes the result using o. Returns an error if raw is
+// empty or not valid Base62.
+//
+// chi:
+//
+//	id, err := optimus.DecodeParam(o, chi.URLParam(r, "id"))
+//
+// gin:
+//
+//	id, err := optimus.DecodeParam(o, c.Param("id"))
+func DecodeParam(o Optimus, raw string) (uint64, error) {
+	if raw == "" {
+		return 0, jsonerror.New(21, "Empty parameter", "Parameter is empty")
+	}
+	return o.DecodeFromString(raw)
+}
+
+// Splits s on commas and decodes each Base62 entry using o, eg. for a
+// query parameter such as "AbC,dEf,ghI". Whitespace around entries is
+// ignored. Returns an empty slice if s is empty, otherwise the first error
+// encountered along with its index.
+func DecodeCSVParam(o Optimus, s string) ([]uint64, error) {
+	if strings.TrimSpace(s) == "" {
+		return []uint64{}, nil
+	}
+
+	entries := strings.Split(s, ",")
+	result := make([]uint64, len(entries))
+	for i, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			return nil, jsonerror.New(21, "Empty parameter", fmt.Sprintf("index %d: Entry is empty", i))
+		}
+
+		n, err := o.DecodeFromString(entry)
+		if err != nil {
+			return nil, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("index %d: %s", i, err.Error()))
+		}
+		result[i] = n
+	}
+	return result, nil
+}
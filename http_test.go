@@ -0,0 +1,134 @@
+package optimus
+
+import (
+	"errors"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Tests that a valid Base62 query parameter is decoded.
+func TestDecodeQueryParam(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint64{0, 15, MAX_INT} {
+		r := httptest.NewRequest("GET", "/users?id="+base62Encode(o.Encode(value)), nil)
+
+		n, err := DecodeQueryParam(o, r, "id")
+		if err != nil || n != value {
+			t.Errorf("%d: -> %d (%v) - FAILED", value, n, err)
+		}
+	}
+}
+
+// Tests that missing, empty and malformed parameters are rejected.
+func TestDecodeQueryParamInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, target := range []string{"/users", "/users?other=abc", "/users?id=", "/users?id=abc-def", "/users?id=zzzzzzzzzzzz"} {
+		r := httptest.NewRequest("GET", target, nil)
+		if _, err := DecodeQueryParam(o, r, "id"); err == nil {
+			t.Errorf("Expected %s to be rejected", target)
+		}
+	}
+}
+
+// Tests that an already extracted parameter is decoded and that empty and
+// malformed values are rejected.
+func TestDecodeParam(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint64{0, 15, MAX_INT} {
+		n, err := DecodeParam(o, o.EncodeToString(value))
+		if err != nil || n != value {
+			t.Errorf("%d: -> %d (%v) - FAILED", value, n, err)
+		}
+	}
+
+	for _, raw := range []string{"", "abc-def", "abc def", "zzzzzzzzzzzz"} {
+		if _, err := DecodeParam(o, raw); err == nil {
+			t.Errorf("Expected %q to be rejected", raw)
+		}
+	}
+}
+
+// Tests that comma-separated lists are decoded in order.
+func TestDecodeCSVParam(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	ids := []uint64{15, 0, MAX_INT, 15}
+
+	var parts []string
+	for _, id := range ids {
+		parts = append(parts, base62Encode(o.Encode(id)))
+	}
+
+	for _, s := range []string{strings.Join(parts, ","), " " + strings.Join(parts, " , ") + " "} {
+		decoded, err := DecodeCSVParam(o, s)
+		if err != nil || len(decoded) != len(ids) {
+			t.Fatalf("%q: expected %v. Got %v (%v)", s, ids, decoded, err)
+		}
+		for i := range ids {
+			if decoded[i] != ids[i] {
+				t.Errorf("%q: expected %v. Got %v", s, ids, decoded)
+			}
+		}
+	}
+
+	if decoded, err := DecodeCSVParam(o, ""); err != nil || len(decoded) != 0 {
+		t.Errorf("Expected empty result. Got %v (%v)", decoded, err)
+	}
+}
+
+// Tests that empty and malformed entries are rejected.
+func TestDecodeCSVParamInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, s := range []string{"AbC,,ghI", "AbC, ,ghI", "AbC,", ",AbC", "AbC,dEf,g-I"} {
+		if _, err := DecodeCSVParam(o, s); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+}
+
+// Tests that errors from the package map to the documented status codes.
+func TestHTTPStatus(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	_, invalid := o.DecodeFromString("!")
+	_, signature := o.DecodeSigned(o.EncodeSigned(15, []byte("key"))+"x", []byte("key"))
+	_, tenant := NewTenantResolver(map[string]Optimus{}).Encode("missing", 15)
+	_, recovered := Try(func() Optimus { return New(2, 1, 1163945558) })
+	_, local := GenerateSeedFrom(StubPrimeSource{})
+
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusOK},
+		{invalid, http.StatusBadRequest},
+		{signature, http.StatusBadRequest},
+		{ErrZeroID, http.StatusBadRequest},
+		{fmt.Errorf("wrapped: %w", ErrSignatureInvalid), http.StatusBadRequest},
+		{tenant, http.StatusNotFound},
+		{ErrEvenPrime, http.StatusInternalServerError},
+		{recovered, http.StatusInternalServerError},
+		{local, http.StatusInternalServerError},
+		{errors.New("unrelated"), http.StatusInternalServerError},
+	}
+
+	for i, test := range tests {
+		if status := HTTPStatus(test.err); status != test.status {
+			t.Errorf("%d: %v: Expected %d. Got %d", i, test.err, test.status, status)
+		}
+	}
+
+	//Every mapped code is reached through a RecoveredError too
+	for code, status := range httpStatuses {
+		je := RecoveredError{jsonerror.New(code, "Test", "")}
+		if got := HTTPStatus(je); got != status {
+			t.Errorf("Code %d: Expected %d. Got %d", code, status, got)
+		}
+	}
+}
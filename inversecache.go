@@ -0,0 +1,45 @@
+package optimus
+
+import (
+	"sync"
+)
+
+// Whether NewCalculated memoizes the modInverse of each prime it is given
+// so that repeatedly constructing an Optimus with the same prime skips the
+// primality test and the inverse calculation. Defaults to false.
+// Each distinct prime costs a map entry which is kept until
+// ClearModInverseCache is called, so leave this disabled if primes come
+// from untrusted input.
+var CacheModInverses = false
+
+// Maps a prime to its modInverse. Only primes which passed validation are
+// stored.
+var modInverseCache sync.Map
+
+// Removes every entry memoized while CacheModInverses was enabled.
+func ClearModInverseCache() {
+	modInverseCache.Range(func(key, value interface{}) bool {
+		modInverseCache.Delete(key)
+		return true
+	})
+}
+
+// Returns the memoized modInverse of prime if CacheModInverses is enabled.
+func cachedModInverse(prime uint64) (uint64, bool) {
+	if !CacheModInverses {
+		return 0, false
+	}
+	inverse, ok := modInverseCache.Load(prime)
+	if !ok {
+		return 0, false
+	}
+	return inverse.(uint64), true
+}
+
+// Memoizes the modInverse of a validated prime if CacheModInverses is
+// enabled.
+func storeModInverse(prime uint64, inverse uint64) {
+	if CacheModInverses {
+		modInverseCache.Store(prime, inverse)
+	}
+}
@@ -0,0 +1,71 @@
+package optimus
+
+import (
+	"sync"
+	"testing"
+)
+
+// Tests that memoized inverses are used concurrently and can be cleared.
+func TestCacheModInverses(t *testing.T) {
+	defer func() {
+		CacheModInverses = false
+		ClearModInverseCache()
+	}()
+	CacheModInverses = true
+
+	primes := []uint64{1580030173, 2123809381, 4294967291, 9223372036854775783}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				prime := primes[i%len(primes)]
+				o := NewCalculated(prime, 1163945558)
+				if o.Prime()*o.ModInverse() != 1 {
+					t.Errorf("%d: Invalid modInverse %d", prime, o.ModInverse())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, prime := range primes {
+		if inverse, ok := cachedModInverse(prime); !ok || inverse != ModInverse(prime) {
+			t.Errorf("%d: Expected %d to be memoized. Got %d (%t)", prime, ModInverse(prime), inverse, ok)
+		}
+	}
+
+	ClearModInverseCache()
+	if _, ok := cachedModInverse(primes[0]); ok {
+		t.Errorf("Expected the cache to be cleared")
+	}
+
+	//Composites are never memoized
+	func() {
+		defer func() { recover() }()
+		NewCalculated(1580030175, 1163945558)
+	}()
+	if _, ok := modInverseCache.Load(uint64(1580030175)); ok {
+		t.Errorf("Expected composite not to be memoized")
+	}
+}
+
+func BenchmarkNewCalculated(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewCalculated(9223372036854775783, 1163945558)
+	}
+}
+
+func BenchmarkNewCalculatedCached(b *testing.B) {
+	defer func() {
+		CacheModInverses = false
+		ClearModInverseCache()
+	}()
+	CacheModInverses = true
+
+	for i := 0; i < b.N; i++ {
+		NewCalculated(9223372036854775783, 1163945558)
+	}
+}
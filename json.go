@@ -0,0 +1,36 @@
+package optimus
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"strconv"
+)
+
+// Encodes an id sent as a JSON number string. The number is parsed as a
+// uint64 so ids above 2^53 don't lose precision as they would if decoded
+// into a float64.
+func (this Optimus) EncodeJSONNumber(s json.Number) (json.Number, error) {
+	n, err := parseJSONNumber(s)
+	if err != nil {
+		return "", err
+	}
+	return json.Number(strconv.FormatUint(this.Encode(n), 10)), nil
+}
+
+// Decodes a value produced by EncodeJSONNumber.
+func (this Optimus) DecodeJSONNumber(s json.Number) (json.Number, error) {
+	n, err := parseJSONNumber(s)
+	if err != nil {
+		return "", err
+	}
+	return json.Number(strconv.FormatUint(this.Decode(n), 10)), nil
+}
+
+func parseJSONNumber(s json.Number) (uint64, error) {
+	n, err := strconv.ParseUint(string(s), 10, 64)
+	if err != nil {
+		return 0, jsonerror.New(13, "Invalid number", fmt.Sprintf("%q is not an unsigned 64-bit integer", string(s)))
+	}
+	return n, nil
+}
@@ -0,0 +1,59 @@
+package optimus
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// Tests that ids above 2^53 round-trip exactly through json.Number where a
+// float64 would corrupt them.
+func TestEncodeJSONNumber(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, s := range []json.Number{"0", "15", "9007199254740993", "18446744073709551615"} {
+		encoded, err := o.EncodeJSONNumber(s)
+		if err != nil {
+			t.Errorf("%s - FAILED: %v", s, err)
+			continue
+		}
+
+		decoded, err := o.DecodeJSONNumber(encoded)
+		if err != nil || decoded != s {
+			t.Errorf("%s: %s -> %s (%v) - FAILED", s, encoded, decoded, err)
+		}
+	}
+
+	//2^53 + 1 can not be represented by a float64
+	var f float64
+	json.Unmarshal([]byte("9007199254740993"), &f)
+	if uint64(f) == 9007199254740993 {
+		t.Errorf("Expected float64 to lose precision")
+	}
+
+	//Round-trip through a JSON document using UseNumber
+	var v struct{ ID json.Number }
+	d := json.NewDecoder(bytes.NewReader([]byte(`{"ID": 9007199254740993}`)))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	encoded, _ := o.EncodeJSONNumber(v.ID)
+	if decoded, _ := o.DecodeJSONNumber(encoded); decoded != "9007199254740993" {
+		t.Errorf("Expected 9007199254740993. Got %s", decoded)
+	}
+}
+
+// Tests that invalid numbers are rejected.
+func TestEncodeJSONNumberInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, s := range []json.Number{"", "-1", "1.5", "1e3", "18446744073709551616", "abc"} {
+		if _, err := o.EncodeJSONNumber(s); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+		if _, err := o.DecodeJSONNumber(s); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+}
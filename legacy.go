@@ -0,0 +1,14 @@
+package optimus
+
+// Decodes n using the arithmetic of the original 31-bit scheme (MAXID =
+// 2147483647 in Step 1 of the README), where products were masked with
+// MAXID instead of MAX_INT. Seeds whose modInverse was calculated so that
+// (PRIME * INVERSE) & MAXID == 1 do not decode correctly with Decode.
+//
+// To migrate, decode historical ids with LegacyDecode and store or
+// re-issue them using a seed from NewCalculated or GenerateSeedLocal,
+// whose modInverse is correct modulo 2^64. Ids encoded with the new seed
+// must be decoded with Decode.
+func (this Optimus) LegacyDecode(n uint64) uint64 {
+	return ((n ^ this.random) * this.modInverse) & MAX_INT_31
+}
@@ -0,0 +1,31 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that ids encoded with the 31-bit README seed decode with
+// LegacyDecode but not with Decode.
+func TestLegacyDecode(t *testing.T) {
+	o := New(1580030173, 59260789, 1163945558)
+
+	//Encoded with the original 31-bit arithmetic
+	if got := o.LegacyDecode(1103647397); got != 15 {
+		t.Errorf("Expected 1103647397 to decode to 15. Got %d", got)
+	}
+	//Debug builds panic in Decode since the seed is inconsistent modulo 2^64
+	if !debug && o.Decode(1103647397) == 15 {
+		t.Errorf("Expected Decode to differ from LegacyDecode")
+	}
+
+	legacy, err := NewOptimus32(1580030173, 59260789, 1163945558, 31)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []uint32{0, 1, 15, 1 << 20, MAX_INT_31} {
+		encoded := legacy.Encode32(value)
+		if got := o.LegacyDecode(uint64(encoded)); got != uint64(value) {
+			t.Errorf("%d: %d -> %d - FAILED", value, encoded, got)
+		}
+	}
+}
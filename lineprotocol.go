@@ -0,0 +1,51 @@
+package optimus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Serves a line protocol on rw until it returns EOF, eg. for a small
+// network service wrapping the package. Each line is a command:
+//
+//	E 12345   Encodes 12345 and replies with the result
+//	D 67890   Decodes 67890 and replies with the result
+//
+// Each reply is written before the next command is read, so a client which
+// does not read its replies is slowed down rather than buffered for.
+// Malformed commands are answered with a line starting with "ERR " and the
+// connection is kept open. Returns nil on EOF and an error if reading or
+// writing fails.
+func ServeLineProtocol(o Optimus, rw io.ReadWriter) error {
+	scanner := bufio.NewScanner(rw)
+	for scanner.Scan() {
+		if _, err := io.WriteString(rw, lineReply(o, scanner.Text())+"\n"); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Returns the reply to a single command of the line protocol.
+func lineReply(o Optimus, line string) string {
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return fmt.Sprintf("ERR expected a command and a number. Got %q", line)
+	}
+
+	n, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return fmt.Sprintf("ERR %q is not an unsigned 64-bit integer", fields[1])
+	}
+
+	switch fields[0] {
+	case "E":
+		return strconv.FormatUint(o.Encode(n), 10)
+	case "D":
+		return strconv.FormatUint(o.Decode(n), 10)
+	}
+	return fmt.Sprintf("ERR unknown command %q. Expected E or D", fields[0])
+}
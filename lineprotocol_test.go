@@ -0,0 +1,71 @@
+package optimus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Combines a reader and a writer into an io.ReadWriter.
+type testReadWriter struct {
+	io.Reader
+	io.Writer
+}
+
+// Tests encode, decode and malformed commands.
+func TestServeLineProtocol(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	input := fmt.Sprintf("E 15\nD %d\n\nE\nE 15 16\nE -1\nE abc\nX 15\nE 18446744073709551616\n  E   7  \nD 1", o.Encode(42))
+	out := new(bytes.Buffer)
+	if err := ServeLineProtocol(o, testReadWriter{strings.NewReader(input), out}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	expected := []string{
+		fmt.Sprint(o.Encode(15)),
+		"42",
+		"ERR", //empty line
+		"ERR", //missing number
+		"ERR", //too many fields
+		"ERR", //negative
+		"ERR", //not a number
+		"ERR", //unknown command
+		"ERR", //overflow
+		fmt.Sprint(o.Encode(7)),
+		fmt.Sprint(o.Decode(1)), //no trailing newline
+	}
+
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d replies. Got %q", len(expected), lines)
+	}
+	for i, line := range lines {
+		if expected[i] == "ERR" {
+			if !strings.HasPrefix(line, "ERR ") {
+				t.Errorf("%d: Expected an error line. Got %q", i, line)
+			}
+		} else if line != expected[i] {
+			t.Errorf("%d: Expected %q. Got %q - FAILED", i, expected[i], line)
+		}
+	}
+}
+
+type failingReadWriter struct {
+	io.Reader
+}
+
+func (this failingReadWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// Tests that write errors stop the server.
+func TestServeLineProtocolWriteError(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	if err := ServeLineProtocol(o, failingReadWriter{strings.NewReader("E 15\nE 16\n")}); err == nil {
+		t.Errorf("Expected the write error to be returned")
+	}
+}
@@ -0,0 +1,29 @@
+package optimus
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// Writes a CSV mapping each id to its encoding, eg. to persist the mapping
+// for an existing table during a migration. The first row is the header
+// "real,encoded,encoded_base62" followed by one row per id in order.
+// DO NOT DEVULGE THE OUTPUT! A handful of rows is enough to recover the seed.
+func ExportMapping(o Optimus, ids []uint64, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"real", "encoded", "encoded_base62"}); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		encoded := o.Encode(id)
+		row := []string{strconv.FormatUint(id, 10), strconv.FormatUint(encoded, 10), base62Encode(encoded)}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,55 @@
+package optimus
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (this failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// Tests that the CSV matches Encode and round-trips.
+func TestExportMapping(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	ids := []uint64{0, 1, 15, 1 << 63, MAX_INT}
+
+	buf := new(bytes.Buffer)
+	if err := ExportMapping(o, ids, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(ids)+1 || rows[0][0] != "real" || rows[0][1] != "encoded" || rows[0][2] != "encoded_base62" {
+		t.Fatalf("Unexpected CSV %v", rows)
+	}
+
+	for i, row := range rows[1:] {
+		id, _ := strconv.ParseUint(row[0], 10, 64)
+		encoded, _ := strconv.ParseUint(row[1], 10, 64)
+		if id != ids[i] || encoded != o.Encode(ids[i]) || row[2] != o.EncodeToString(ids[i]) {
+			t.Errorf("%d: Unexpected row %v - FAILED", ids[i], row)
+		}
+
+		fromBase62, err := o.DecodeFromString(row[2])
+		if err != nil || o.Decode(encoded) != id || fromBase62 != id {
+			t.Errorf("%v: Expected to decode to %d - FAILED", row, id)
+		}
+	}
+}
+
+// Tests that write errors are returned.
+func TestExportMappingWriteError(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	if err := ExportMapping(o, []uint64{1, 2, 3}, failingWriter{}); err == nil {
+		t.Errorf("Expected the write error to be returned")
+	}
+}
@@ -0,0 +1,244 @@
+package optimus
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Struct tag marking the fields obfuscated by MarshalObfuscated, eg.
+//
+//	ID uint64 `json:"id" optimus:"id"`
+const STRUCT_TAG = "optimus"
+
+var stringType = reflect.TypeOf("")
+
+// Encodes the exported uint64, *uint64 and []uint64 fields tagged with
+// `optimus:"id"` and then marshals v using encoding/json. The encoded ids
+// are written as JSON strings because they are usually above 2^53, which
+// JavaScript can not represent exactly. Tagged fields are found through
+// pointers, slices and nested or embedded structs, but not through maps or
+// interfaces. v itself is not modified. Returns an error if a tagged field
+// has another type or a struct containing tagged fields refers to itself.
+func MarshalObfuscated(o Optimus, v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return json.Marshal(nil)
+	}
+
+	t, err := mirrorType(rv.Type(), false, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	m := reflect.New(t).Elem()
+	if err := copyTagged(m, rv, false, o.Encode); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m.Interface())
+}
+
+// Unmarshals data into v using encoding/json and then decodes the tagged
+// fields. It reverses MarshalObfuscated, so the tagged ids must be JSON
+// strings. v must be a non-nil pointer.
+func UnmarshalDeobfuscated(o Optimus, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return json.Unmarshal(data, v)
+	}
+
+	t, err := mirrorType(rv.Type().Elem(), false, map[reflect.Type]bool{})
+	if err != nil {
+		return err
+	}
+
+	//Start from the current value so that fields missing from data are kept
+	m := reflect.New(t)
+	if err := copyTagged(m.Elem(), rv.Elem(), false, func(n uint64) uint64 { return n }); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, m.Interface()); err != nil {
+		return err
+	}
+	return copyTagged(rv.Elem(), m.Elem(), false, o.Decode)
+}
+
+// Returns the type encoding/json marshals in place of t: tagged fields
+// become strings and structs containing them become unnamed structs with
+// the same JSON fields. Types without tagged fields are returned as is.
+// building holds the structs being mirrored, to detect recursive types.
+func mirrorType(t reflect.Type, tagged bool, building map[reflect.Type]bool) (reflect.Type, error) {
+	if tagged {
+		switch {
+		case t.Kind() == reflect.Uint64:
+			return stringType, nil
+		case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Uint64:
+			return reflect.PtrTo(stringType), nil
+		case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint64:
+			return reflect.SliceOf(stringType), nil
+		}
+		return nil, jsonerror.New(37, "Invalid tag", fmt.Sprintf("Tagged fields must be uint64, *uint64 or []uint64. Got %s", t))
+	}
+
+	if !containsTagged(t, map[reflect.Type]bool{}) {
+		return t, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := mirrorType(t.Elem(), false, building)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PtrTo(elem), nil
+
+	case reflect.Slice:
+		elem, err := mirrorType(t.Elem(), false, building)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	}
+	return mirrorStruct(t, building)
+}
+
+// Returns the mirror of the struct type t. Embedded structs are always
+// mirrored so that their fields are still promoted.
+func mirrorStruct(t reflect.Type, building map[reflect.Type]bool) (reflect.Type, error) {
+	if building[t] {
+		return nil, jsonerror.New(37, "Invalid tag", fmt.Sprintf("%s refers to itself", t))
+	}
+	building[t] = true
+	defer delete(building, t)
+
+	var fields []reflect.StructField
+	for _, i := range marshaledFields(t) {
+		field := t.Field(i)
+
+		embedded := field.Anonymous && embeddedStruct(field.Type) != nil
+
+		var typ reflect.Type
+		var err error
+		if embedded {
+			typ, err = mirrorStruct(embeddedStruct(field.Type), building)
+			if err == nil && field.Type.Kind() == reflect.Ptr {
+				typ = reflect.PtrTo(typ)
+			}
+		} else {
+			typ, err = mirrorType(field.Type, field.Tag.Get(STRUCT_TAG) == "id", building)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		//reflect.StructOf only accepts exported names
+		name := strings.ToUpper(field.Name[:1]) + field.Name[1:]
+		fields = append(fields, reflect.StructField{Name: name, Type: typ, Tag: field.Tag, Anonymous: embedded})
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// Returns the struct type t or t points to, or nil if there is none.
+func embeddedStruct(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// Returns the indices of the fields of the struct type t which
+// encoding/json marshals: the exported fields and embedded structs.
+func marshaledFields(t reflect.Type) []int {
+	var indices []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath == "" || field.Anonymous && field.Type.Kind() == reflect.Struct {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// Reports whether t has tagged fields, directly or through pointers, slices
+// and structs.
+func containsTagged(t reflect.Type, seen map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		return containsTagged(t.Elem(), seen)
+
+	case reflect.Struct:
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		for _, i := range marshaledFields(t) {
+			field := t.Field(i)
+			if field.Tag.Get(STRUCT_TAG) == "id" || containsTagged(field.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Copies src to dst, where one of them has a type returned by mirrorType,
+// applying f to the tagged ids on the way.
+func copyTagged(dst reflect.Value, src reflect.Value, tagged bool, f func(uint64) uint64) error {
+	if !tagged && dst.Type() == src.Type() {
+		dst.Set(src)
+		return nil
+	}
+
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return copyTagged(dst.Elem(), src.Elem(), tagged, f)
+
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			if err := copyTagged(dst.Index(i), src.Index(i), tagged, f); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Struct:
+		dstFields, srcFields := marshaledFields(dst.Type()), marshaledFields(src.Type())
+		for i, j := range srcFields {
+			tagged := src.Type().Field(j).Tag.Get(STRUCT_TAG) == "id"
+			if err := copyTagged(dst.Field(dstFields[i]), src.Field(j), tagged, f); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Uint64:
+		dst.SetString(strconv.FormatUint(f(src.Uint()), 10))
+		return nil
+	}
+
+	//A tagged id read back from the JSON
+	n, err := parseJSONNumber(json.Number(src.String()))
+	if err != nil {
+		return err
+	}
+	dst.SetUint(f(n))
+	return nil
+}
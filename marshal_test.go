@@ -0,0 +1,131 @@
+package optimus
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testAudit struct {
+	CreatedBy uint64 `json:"created_by" optimus:"id"`
+}
+
+type testAuthor struct {
+	ID   uint64 `json:"id" optimus:"id"`
+	Name string `json:"name"`
+}
+
+type testComment struct {
+	ID     uint64      `json:"id" optimus:"id"`
+	Author *testAuthor `json:"author"`
+	Body   string      `json:"body"`
+}
+
+type testPost struct {
+	testAudit
+	ID         uint64        `json:"id" optimus:"id"`
+	ParentID   *uint64       `json:"parent_id" optimus:"id"`
+	RelatedIDs []uint64      `json:"related_ids" optimus:"id"`
+	Views      uint64        `json:"views"`
+	Author     testAuthor    `json:"author"`
+	Comments   []testComment `json:"comments"`
+}
+
+type testPostsResponse struct {
+	Posts []*testPost `json:"posts"`
+	Total int         `json:"total"`
+}
+
+// Returns a realistic nested API response.
+func testResponse() testPostsResponse {
+	parent := uint64(7)
+	return testPostsResponse{
+		Posts: []*testPost{
+			{
+				testAudit:  testAudit{CreatedBy: 1},
+				ID:         15,
+				ParentID:   &parent,
+				RelatedIDs: []uint64{3, 4},
+				Views:      100,
+				Author:     testAuthor{ID: 1, Name: "ann"},
+				Comments: []testComment{
+					{ID: 20, Author: &testAuthor{ID: 2, Name: "bob"}, Body: "first"},
+					{ID: 21, Body: "anonymous"},
+				},
+			},
+			nil,
+		},
+		Total: 1,
+	}
+}
+
+// Tests that tagged fields are obfuscated in the JSON, the input is not
+// modified and UnmarshalDeobfuscated restores the original.
+func TestMarshalObfuscated(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	response := testResponse()
+
+	data, err := MarshalObfuscated(o, &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(response, testResponse()) {
+		t.Errorf("Expected the input not to be modified. Got %+v", response)
+	}
+
+	//Ids are written as strings so that JavaScript does not round them.
+	//Untagged fields are unchanged
+	expected := fmt.Sprintf(`{"posts":[{"created_by":"%d","id":"%d","parent_id":"%d","related_ids":["%d","%d"],"views":100,`+
+		`"author":{"id":"%d","name":"ann"},"comments":[{"id":"%d","author":{"id":"%d","name":"bob"},"body":"first"},`+
+		`{"id":"%d","author":null,"body":"anonymous"}]},null],"total":1}`,
+		o.Encode(1), o.Encode(15), o.Encode(7), o.Encode(3), o.Encode(4),
+		o.Encode(1), o.Encode(20), o.Encode(2),
+		o.Encode(21))
+	if string(data) != expected {
+		t.Errorf("Expected %s. Got %s", expected, data)
+	}
+
+	var decoded testPostsResponse
+	if err := UnmarshalDeobfuscated(o, data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, testResponse()) {
+		t.Errorf("Expected %+v. Got %+v", testResponse(), decoded)
+	}
+}
+
+// Tests that tags on unsupported types and invalid targets are rejected.
+func TestMarshalObfuscatedInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	type badTag struct {
+		ID string `json:"id" optimus:"id"`
+	}
+	if _, err := MarshalObfuscated(o, badTag{ID: "15"}); err == nil {
+		t.Errorf("Expected a tagged string to be rejected")
+	}
+	if err := UnmarshalDeobfuscated(o, []byte(`{"id":"15"}`), &badTag{}); err == nil {
+		t.Errorf("Expected a tagged string to be rejected")
+	}
+
+	type node struct {
+		ID       uint64 `json:"id" optimus:"id"`
+		Children []node `json:"children"`
+	}
+	if _, err := MarshalObfuscated(o, node{ID: 15}); err == nil {
+		t.Errorf("Expected a recursive type to be rejected")
+	}
+
+	if err := UnmarshalDeobfuscated(o, []byte(`{"id":"x"}`), &testAuthor{}); err == nil {
+		t.Errorf("Expected an invalid id to be rejected")
+	}
+
+	if err := UnmarshalDeobfuscated(o, []byte(`{"id":15}`), testAuthor{}); err == nil {
+		t.Errorf("Expected a non-pointer to be rejected")
+	}
+
+	if data, err := MarshalObfuscated(o, nil); err != nil || string(data) != "null" {
+		t.Errorf("Expected null. Got %s (%v)", data, err)
+	}
+}
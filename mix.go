@@ -0,0 +1,86 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Finalizer selects the bit-mixing step applied by EncodeMixed after the
+// multiplication and random number.
+type Finalizer uint8
+
+const (
+	// No extra mixing. EncodeMixed is the same as Encode.
+	FINALIZER_NONE Finalizer = iota
+
+	// The finalizer of SplitMix64 (Stafford's Mix13).
+	FINALIZER_SPLITMIX64
+
+	// The fmix64 finalizer of MurmurHash3.
+	FINALIZER_MURMUR3
+)
+
+var (
+	splitMix64Inverse1 = inverse64(0xbf58476d1ce4e5b9)
+	splitMix64Inverse2 = inverse64(0x94d049bb133111eb)
+	murmur3Inverse1    = inverse64(0xff51afd7ed558ccd)
+	murmur3Inverse2    = inverse64(0xc4ceb9fe1a85ec53)
+)
+
+// Encodes n like Encode and then mixes the bits using finalizer. Knuth's
+// single multiplication has weak avalanche: the high bits of n only affect
+// the high bits of the output, eg. flipping the top bit of n only flips the
+// top bit of the output. The finalizer makes every input bit affect about
+// half of the output bits. Every step is invertible so DecodeMixed recovers
+// n exactly. Panics if finalizer is unknown.
+// NB: The finalizers are public and keyless. They improve diffusion but do
+// not add any secrecy beyond the seed.
+func (this Optimus) EncodeMixed(n uint64, finalizer Finalizer) uint64 {
+	z := this.Encode(n)
+	switch finalizer {
+	case FINALIZER_NONE:
+		return z
+	case FINALIZER_SPLITMIX64:
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	case FINALIZER_MURMUR3:
+		z = (z ^ (z >> 33)) * 0xff51afd7ed558ccd
+		z = (z ^ (z >> 33)) * 0xc4ceb9fe1a85ec53
+		return z ^ (z >> 33)
+	}
+	panic(unknownFinalizerError(finalizer))
+}
+
+// Decodes a number produced by EncodeMixed with the same finalizer. Panics
+// if finalizer is unknown.
+func (this Optimus) DecodeMixed(n uint64, finalizer Finalizer) uint64 {
+	z := n
+	switch finalizer {
+	case FINALIZER_NONE:
+	case FINALIZER_SPLITMIX64:
+		z = unxorshift(z, 31) * splitMix64Inverse2
+		z = unxorshift(z, 27) * splitMix64Inverse1
+		z = unxorshift(z, 30)
+	case FINALIZER_MURMUR3:
+		z = unxorshift(z, 33) * murmur3Inverse2
+		z = unxorshift(z, 33) * murmur3Inverse1
+		z = unxorshift(z, 33)
+	default:
+		panic(unknownFinalizerError(finalizer))
+	}
+	return this.Decode(z)
+}
+
+// Returns x such that x ^ (x >> shift) == z.
+func unxorshift(z uint64, shift uint) uint64 {
+	x := z
+	for i := shift; i < 64; i += shift {
+		x = z ^ (x >> shift)
+	}
+	return x
+}
+
+func unknownFinalizerError(finalizer Finalizer) error {
+	return jsonerror.New(33, "Unknown finalizer", fmt.Sprintf("finalizer=%d", finalizer))
+}
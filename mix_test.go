@@ -0,0 +1,64 @@
+package optimus
+
+import (
+	"math/bits"
+	"testing"
+)
+
+// Tests that every finalizer round-trips.
+func TestEncodeMixed(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	for _, finalizer := range []Finalizer{FINALIZER_NONE, FINALIZER_SPLITMIX64, FINALIZER_MURMUR3} {
+		for _, value := range []uint64{0, 1, 15, 1 << 31, 1 << 63, MAX_INT} {
+			hashed := o.EncodeMixed(value, finalizer)
+			unhashed := o.DecodeMixed(hashed, finalizer)
+			if unhashed != value {
+				t.Errorf("Finalizer %d: %d: %d -> %d - FAILED", finalizer, value, hashed, unhashed)
+			}
+		}
+	}
+
+	if o.EncodeMixed(15, FINALIZER_NONE) != o.Encode(15) {
+		t.Errorf("Expected FINALIZER_NONE to match Encode")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected an unknown finalizer to panic")
+		}
+	}()
+	o.EncodeMixed(15, Finalizer(99))
+}
+
+// Tests that the finalizers improve avalanche. Adjacent inputs should
+// differ in about half of their output bits, and flipping any single input
+// bit should flip about half of the output bits.
+func TestEncodeMixedAvalanche(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	const samples = 1000
+
+	for _, finalizer := range []Finalizer{FINALIZER_SPLITMIX64, FINALIZER_MURMUR3} {
+		var adjacent int
+		for n := uint64(0); n < samples; n++ {
+			adjacent += bits.OnesCount64(o.EncodeMixed(n, finalizer) ^ o.EncodeMixed(n+1, finalizer))
+		}
+		if mean := float64(adjacent) / samples; mean < 30 || mean > 34 {
+			t.Errorf("Finalizer %d: Expected adjacent inputs to differ in about 32 bits. Got %.2f - FAILED", finalizer, mean)
+		}
+
+		for bit := uint(0); bit < 64; bit++ {
+			var flipped int
+			for n := uint64(0); n < samples; n++ {
+				flipped += bits.OnesCount64(o.EncodeMixed(n, finalizer) ^ o.EncodeMixed(n^(1<<bit), finalizer))
+			}
+			if mean := float64(flipped) / samples; mean < 28 || mean > 36 {
+				t.Errorf("Finalizer %d: Expected bit %d to flip about 32 bits. Got %.2f - FAILED", finalizer, bit, mean)
+			}
+		}
+	}
+
+	//Without a finalizer the top bit only flips the top bit
+	if diff := o.Encode(15) ^ o.Encode(15^(1<<63)); diff != 1<<63 {
+		t.Errorf("Expected Encode to have weak avalanche. Got %x", diff)
+	}
+}
@@ -0,0 +1,161 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"math/big"
+	"math/bits"
+)
+
+// Modular is a variant of Optimus which works modulo an arbitrary modulus
+// instead of 2^64, eg. a prime modulus matching a specific id space.
+// Odd moduli use Montgomery multiplication to avoid 128-bit division.
+type Modular struct {
+	prime      uint64
+	modInverse uint64
+	random     uint64
+	modulus    uint64
+
+	//Montgomery constants. Only set if the modulus is odd.
+	montNegInv     uint64 // -modulus^-1 mod 2^64
+	montPrime      uint64 // prime * 2^64 mod modulus
+	montModInverse uint64 // modInverse * 2^64 mod modulus
+}
+
+// Returns a Modular which encodes n as (n*prime + random) mod modulus.
+// The prime must be coprime to the modulus so that it has a modular
+// inverse. Returns ErrPrimeTooLarge if the prime is not less than the
+// modulus, and an error if random is not less than the modulus.
+func NewModular(prime uint64, random uint64, modulus uint64) (Modular, error) {
+	if modulus < 2 {
+		return Modular{}, jsonerror.New(10, "Invalid modulus", fmt.Sprintf("modulus=%d. Modulus must be at least 2", modulus))
+	}
+
+	if prime >= modulus {
+		return Modular{}, ErrPrimeTooLarge
+	}
+
+	if !isPrime(prime) {
+		return Modular{}, notPrimeError(prime)
+	}
+
+	if random >= modulus {
+		return Modular{}, jsonerror.New(16, "Invalid random", fmt.Sprintf("random=%d. Must be less than the modulus %d", random, modulus))
+	}
+
+	var inverse big.Int
+	p := new(big.Int).SetUint64(prime)
+	m := new(big.Int).SetUint64(modulus)
+	if inverse.ModInverse(p, m) == nil {
+		return Modular{}, jsonerror.New(10, "Invalid modulus", fmt.Sprintf("prime=%d modulus=%d. Prime and modulus are not coprime", prime, modulus))
+	}
+
+	o := Modular{prime: prime, modInverse: inverse.Uint64(), random: random, modulus: modulus}
+	if modulus&1 == 1 {
+		r := bits.Rem64(1, 0, modulus) // 2^64 mod modulus
+		o.montNegInv = -inverse64(modulus)
+		o.montPrime = mulMod(prime, r, modulus)
+		o.montModInverse = mulMod(o.modInverse, r, modulus)
+	}
+	return o, nil
+}
+
+// Encodes n as (n*prime + random) mod modulus. n should be less than the
+// modulus; larger values are reduced first and will not decode back to n.
+// Builds using the optimus_debug tag panic instead.
+func (this Modular) Encode(n uint64) uint64 {
+	if debug {
+		assertInDomain("Modular.Encode", n, this.modulus-1)
+	}
+	if n >= this.modulus {
+		n %= this.modulus
+	}
+	if this.montNegInv != 0 {
+		return addMod(this.montMul(n, this.montPrime), this.random, this.modulus)
+	}
+	return addMod(mulMod(n, this.prime, this.modulus), this.random, this.modulus)
+}
+
+// Decodes a number produced by Encode. Builds using the optimus_debug tag
+// panic if n is not less than the modulus.
+func (this Modular) Decode(n uint64) uint64 {
+	if debug {
+		assertInDomain("Modular.Decode", n, this.modulus-1)
+	}
+	if n >= this.modulus {
+		n %= this.modulus
+	}
+	if this.montNegInv != 0 {
+		return this.montMul(subMod(n, this.random, this.modulus), this.montModInverse)
+	}
+	return mulMod(subMod(n, this.random, this.modulus), this.modInverse, this.modulus)
+}
+
+// Returns (a * b * 2^-64) mod modulus for a, b < modulus using Montgomery
+// reduction. Passing b in Montgomery form (b * 2^64 mod modulus) gives
+// (a * b) mod modulus.
+func (this Modular) montMul(a uint64, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	q := lo * this.montNegInv
+	qmHi, qmLo := bits.Mul64(q, this.modulus)
+
+	//lo + qmLo is 0 mod 2^64. Only its carry is needed.
+	_, carry := bits.Add64(lo, qmLo, 0)
+	t, overflow := bits.Add64(hi, qmHi, carry)
+	if overflow != 0 || t >= this.modulus {
+		t -= this.modulus
+	}
+	return t
+}
+
+// Returns the Associated Prime Number. DO NOT DEVULGE THIS NUMBER!
+func (this Modular) Prime() uint64 {
+	return this.prime
+}
+
+// Returns the Associated ModInverse Number. DO NOT DEVULGE THIS NUMBER!
+func (this Modular) ModInverse() uint64 {
+	return this.modInverse
+}
+
+// Returns the Associated Random Number. DO NOT DEVULGE THIS NUMBER!
+func (this Modular) Random() uint64 {
+	return this.random
+}
+
+// Returns the Associated Modulus.
+func (this Modular) Modulus() uint64 {
+	return this.modulus
+}
+
+// Returns the inverse of the odd number m modulo 2^64 using Newton's method.
+func inverse64(m uint64) uint64 {
+	x := m // Correct to 3 bits since m*m = 1 mod 8
+	for i := 0; i < 5; i++ {
+		x *= 2 - m*x // Doubles the number of correct bits
+	}
+	return x
+}
+
+// Returns (a * b) mod m without overflowing.
+func mulMod(a uint64, b uint64, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+}
+
+// Returns (a + b) mod m for a, b < m without overflowing.
+func addMod(a uint64, b uint64, m uint64) uint64 {
+	s, carry := bits.Add64(a, b, 0)
+	if carry != 0 || s >= m {
+		s -= m
+	}
+	return s
+}
+
+// Returns (a - b) mod m for a, b < m.
+func subMod(a uint64, b uint64, m uint64) uint64 {
+	if a >= b {
+		return a - b
+	}
+	return a + (m - b)
+}
@@ -0,0 +1,184 @@
+package optimus
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Tests that Modular round-trips for a prime modulus and stays within it.
+func TestModular(t *testing.T) {
+	moduli := []uint64{
+		1000003,              //small prime
+		2147483647,           //2^31 - 1
+		18446744073709551557, //largest 64-bit prime
+	}
+
+	for _, modulus := range moduli {
+		prime := uint64(1580030173)
+		if prime >= modulus {
+			prime = 7919
+		}
+
+		o, err := NewModular(prime, 1163945558%modulus, modulus)
+		if err != nil {
+			t.Errorf("modulus %d - FAILED: %v", modulus, err)
+			continue
+		}
+
+		for _, value := range []uint64{0, 1, 15, modulus / 2, modulus - 2, modulus - 1} {
+			encoded := o.Encode(value)
+			if encoded >= modulus {
+				t.Errorf("%d encoded to %d which is outside modulus %d", value, encoded, modulus)
+			}
+			if decoded := o.Decode(encoded); decoded != value {
+				t.Errorf("modulus %d: %d: %d -> %d - FAILED", modulus, value, encoded, decoded)
+			}
+		}
+	}
+}
+
+// Tests that every value of a small modulus maps to a distinct output.
+func TestModularBijection(t *testing.T) {
+	o, err := NewModular(7919, 12345, 65521)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[uint64]bool)
+	for n := uint64(0); n < 65521; n++ {
+		encoded := o.Encode(n)
+		if seen[encoded] {
+			t.Fatalf("%d collides on %d", n, encoded)
+		}
+		seen[encoded] = true
+	}
+}
+
+// Tests that a prime which is not coprime to the modulus is rejected.
+func TestModularNotCoprime(t *testing.T) {
+	if _, err := NewModular(7919, 1, 7919*3); err == nil {
+		t.Errorf("Expected gcd(prime, modulus) != 1 to be rejected")
+	}
+
+	if _, err := NewModular(7919, 1, 7919); err == nil {
+		t.Errorf("Expected prime == modulus to be rejected")
+	}
+
+	if _, err := NewModular(7920, 1, 65521); err == nil {
+		t.Errorf("Expected composite to be rejected")
+	}
+
+	if _, err := NewModular(7919, 1, 1); err == nil {
+		t.Errorf("Expected modulus 1 to be rejected")
+	}
+}
+
+// Tests that primes which are not less than the modulus are rejected.
+func TestModularPrimeTooLarge(t *testing.T) {
+	//A 9-digit prime can not be used for a 16-bit domain
+	if _, err := NewModular(999999937, 1, 1<<16); err != ErrPrimeTooLarge {
+		t.Errorf("Expected ErrPrimeTooLarge. Got %v", err)
+	}
+
+	if _, err := NewModular(65537, 1, 65537); err != ErrPrimeTooLarge {
+		t.Errorf("Expected ErrPrimeTooLarge for prime == modulus. Got %v", err)
+	}
+
+	if _, err := NewModular(65521, 1, 1<<16); err != nil {
+		t.Errorf("Expected the largest 16-bit prime to be accepted. Got %v", err)
+	}
+}
+
+// Tests that a random which does not fit in a 16-bit modulus is rejected
+// rather than silently reduced.
+func TestModularInvalidRandom(t *testing.T) {
+	if _, err := NewModular(65521, 1<<16, 1<<16); err == nil {
+		t.Errorf("Expected random == modulus to be rejected")
+	}
+
+	if _, err := NewModular(65521, MAX_INT, 1<<16); err == nil {
+		t.Errorf("Expected an oversized random to be rejected")
+	}
+
+	o, err := NewModular(65521, 1<<16-1, 1<<16)
+	if err != nil {
+		t.Fatalf("Expected the largest 16-bit random to be accepted. Got %v", err)
+	}
+	if o.Random() != 1<<16-1 {
+		t.Errorf("Expected random %d. Got %d - FAILED", 1<<16-1, o.Random())
+	}
+}
+
+// Returns what Modular.Encode computed before Montgomery multiplication.
+func naiveModularEncode(o Modular, n uint64) uint64 {
+	return addMod(mulMod(n%o.modulus, o.prime, o.modulus), o.random, o.modulus)
+}
+
+// Returns what Modular.Decode computed before Montgomery multiplication.
+func naiveModularDecode(o Modular, n uint64) uint64 {
+	return mulMod(subMod(n%o.modulus, o.random, o.modulus), o.modInverse, o.modulus)
+}
+
+// Tests that Montgomery multiplication matches the naive implementation
+// exactly, including moduli above 2^63 where the reduction can overflow.
+func TestModularMontgomery(t *testing.T) {
+	moduli := []uint64{
+		3,
+		1000003,
+		2147483647,
+		1<<63 + 29,           //odd, above 2^63
+		18446744073709551557, //largest 64-bit prime
+		MAX_INT,              //odd composite
+		1 << 40,              //even moduli use the naive path
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for _, modulus := range moduli {
+		prime := uint64(1580030173)
+		if prime >= modulus {
+			prime = 2 //coprime to every odd modulus
+		}
+
+		o, err := NewModular(prime, r.Uint64()%modulus, modulus)
+		if err != nil {
+			t.Errorf("modulus %d - FAILED: %v", modulus, err)
+			continue
+		}
+
+		values := []uint64{0, 1, modulus - 1, MAX_INT}
+		for i := 0; i < 1000; i++ {
+			values = append(values, r.Uint64())
+		}
+
+		for _, value := range values {
+			if debug {
+				//Debug builds panic instead of reducing
+				value %= modulus
+			}
+
+			if got, expected := o.Encode(value), naiveModularEncode(o, value); got != expected {
+				t.Errorf("modulus %d: Encode(%d) = %d. Expected %d - FAILED", modulus, value, got, expected)
+			}
+			if got, expected := o.Decode(value), naiveModularDecode(o, value); got != expected {
+				t.Errorf("modulus %d: Decode(%d) = %d. Expected %d - FAILED", modulus, value, got, expected)
+			}
+		}
+	}
+}
+
+// Keeps benchmark results alive so the calls are not optimized away.
+var benchmarkSink uint64
+
+func BenchmarkModularEncode(b *testing.B) {
+	o, _ := NewModular(1580030173, 1163945558, 18446744073709551557)
+	for i := 0; i < b.N; i++ {
+		benchmarkSink += o.Encode(uint64(i))
+	}
+}
+
+func BenchmarkModularEncodeNaive(b *testing.B) {
+	o, _ := NewModular(1580030173, 1163945558, 18446744073709551557)
+	for i := 0; i < b.N; i++ {
+		benchmarkSink += naiveModularEncode(o, uint64(i))
+	}
+}
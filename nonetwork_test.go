@@ -0,0 +1,25 @@
+//go:build optimus_no_network
+// +build optimus_no_network
+
+package optimus
+
+import (
+	"testing"
+)
+
+// Declaring GenerateSeed here only compiles if the networked generator has
+// been compiled out by the optimus_no_network tag.
+func GenerateSeed() {}
+
+// Tests that local generation still works when the networked generator is
+// compiled out.
+func TestNoNetworkGenerateSeedLocal(t *testing.T) {
+	o, err := GenerateSeedLocal()
+	if err != nil {
+		t.Fatalf("GenerateSeedLocal - FAILED: %v", err)
+	}
+
+	if got := o.Decode(o.Encode(15)); got != 15 {
+		t.Errorf("15: -> %d - FAILED", got)
+	}
+}
@@ -0,0 +1,81 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"strconv"
+)
+
+// Format selects the string form of an encoded value for FormatEncoded and
+// NormalizeEncoded.
+type Format uint8
+
+const (
+	// Decimal digits. Zero-padding, as produced by EncodeNumeric, is accepted.
+	FORMAT_DECIMAL Format = iota
+
+	// Hexadecimal digits without a prefix. Either case is accepted.
+	FORMAT_HEX
+
+	// Base62, as produced by EncodeToString.
+	FORMAT_BASE62
+
+	// Base58 using the Bitcoin alphabet, which omits 0, O, I and l.
+	FORMAT_BASE58
+
+	// Unpadded base64url of the big-endian bytes, as produced by
+	// EncodeBase64URL.
+	FORMAT_BASE64URL
+)
+
+var base58Alphabet = []rune("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+
+// Returns the encoded value (not the id) written in format, eg. to convert
+// an id logged in one format to another.
+// Panics if format is unknown.
+func FormatEncoded(encoded uint64, format Format) string {
+	switch format {
+	case FORMAT_DECIMAL:
+		return strconv.FormatUint(encoded, 10)
+	case FORMAT_HEX:
+		return strconv.FormatUint(encoded, 16)
+	case FORMAT_BASE62:
+		return base62Encode(encoded)
+	case FORMAT_BASE58:
+		return alphabetEncode(encoded, base58Alphabet)
+	case FORMAT_BASE64URL:
+		return base64URLEncode(encoded)
+	}
+	panic(unknownFormatError(format))
+}
+
+// Parses s written in format back to the encoded value without decoding
+// it, so that ids seen in different forms can be correlated (eg. in logs)
+// without knowing the seed. Returns an error if s is not valid in format or
+// format is unknown.
+func NormalizeEncoded(s string, format Format) (uint64, error) {
+	switch format {
+	case FORMAT_DECIMAL, FORMAT_HEX:
+		base := 10
+		if format == FORMAT_HEX {
+			base = 16
+		}
+		n, err := strconv.ParseUint(s, base, 64)
+		if err != nil {
+			return 0, jsonerror.New(4, "Invalid encoded string", err.Error())
+		}
+		return n, nil
+	case FORMAT_BASE62:
+		return base62Decode(s)
+	case FORMAT_BASE58:
+		return alphabetDecode(s, base58Alphabet)
+	case FORMAT_BASE64URL:
+		return base64URLDecode(s)
+	}
+	return 0, unknownFormatError(format)
+}
+
+// Returns the error used when format is not one of the FORMAT constants.
+func unknownFormatError(format Format) error {
+	return jsonerror.New(22, "Unknown format", fmt.Sprintf("format=%d", format))
+}
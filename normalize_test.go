@@ -0,0 +1,78 @@
+package optimus
+
+import (
+	"testing"
+)
+
+var allFormats = []Format{FORMAT_DECIMAL, FORMAT_HEX, FORMAT_BASE62, FORMAT_BASE58, FORMAT_BASE64URL}
+
+// Tests that every string form of an encoded value normalizes to the same
+// integer, including the forms produced by the Optimus methods.
+func TestNormalizeEncoded(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, id := range []uint64{0, 15, 1 << 63, MAX_INT} {
+		encoded := o.Encode(id)
+
+		for _, format := range allFormats {
+			s := FormatEncoded(encoded, format)
+			if n, err := NormalizeEncoded(s, format); err != nil || n != encoded {
+				t.Errorf("%d: format %d: %s -> %d (%v) - FAILED", encoded, format, s, n, err)
+			}
+		}
+
+		numeric, _ := o.EncodeNumeric(id, NUMERIC_MAX_LEN)
+		forms := map[Format]string{
+			FORMAT_DECIMAL:   numeric,
+			FORMAT_BASE62:    o.EncodeToString(id),
+			FORMAT_BASE64URL: o.EncodeBase64URL(id),
+		}
+		for format, s := range forms {
+			if n, err := NormalizeEncoded(s, format); err != nil || n != encoded {
+				t.Errorf("%d: format %d: %s -> %d (%v) - FAILED", encoded, format, s, n, err)
+			}
+		}
+	}
+
+	if s := FormatEncoded(255, FORMAT_HEX); s != "ff" {
+		t.Errorf("Expected ff. Got %s", s)
+	}
+	if n, err := NormalizeEncoded("FF", FORMAT_HEX); err != nil || n != 255 {
+		t.Errorf("Expected 255. Got %d (%v)", n, err)
+	}
+	if s := FormatEncoded(58, FORMAT_BASE58); s != "21" {
+		t.Errorf("Expected 21. Got %s", s)
+	}
+}
+
+// Tests that invalid strings and unknown formats are rejected.
+func TestNormalizeEncodedInvalid(t *testing.T) {
+	tests := []struct {
+		s      string
+		format Format
+	}{
+		{"", FORMAT_DECIMAL},
+		{"-1", FORMAT_DECIMAL},
+		{"18446744073709551616", FORMAT_DECIMAL},
+		{"0x10", FORMAT_HEX},
+		{"10000000000000000", FORMAT_HEX},
+		{"", FORMAT_BASE62},
+		{"a-b", FORMAT_BASE62},
+		{"0OIl", FORMAT_BASE58},
+		{"AAAAAAAAAAA=", FORMAT_BASE64URL},
+		{"15", Format(99)},
+	}
+
+	for _, test := range tests {
+		if n, err := NormalizeEncoded(test.s, test.format); err == nil {
+			t.Errorf("Expected %q in format %d to be rejected. Got %d", test.s, test.format, n)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected an unknown format to panic")
+		}
+	}()
+	FormatEncoded(15, Format(99))
+}
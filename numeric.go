@@ -0,0 +1,48 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"strconv"
+	"strings"
+)
+
+// Number of decimal digits needed for any encoded value (MAX_INT).
+const NUMERIC_MAX_LEN = 20
+
+// Encodes n and returns the result as a decimal string zero-padded to
+// length digits, for systems which only accept digits. Returns an error if
+// length is not positive or the encoded value needs more than length
+// digits. Use NUMERIC_MAX_LEN so that every value fits.
+func (this Optimus) EncodeNumeric(n uint64, length int) (string, error) {
+	if length < 1 {
+		return "", jsonerror.New(12, "Out of domain", fmt.Sprintf("length=%d. Must be positive", length))
+	}
+
+	s := strconv.FormatUint(this.Encode(n), 10)
+	if len(s) > length {
+		return "", jsonerror.New(12, "Out of domain", fmt.Sprintf("n=%d encodes to %d digits. Must not exceed %d", n, len(s), length))
+	}
+	return strings.Repeat("0", length-len(s)) + s, nil
+}
+
+// Decodes a string produced by EncodeNumeric with the same length. Returns
+// an error if s is not length digits long or contains anything other than
+// digits.
+func (this Optimus) DecodeNumeric(s string, length int) (uint64, error) {
+	if len(s) != length {
+		return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q must be %d digits long", s, length))
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q must only contain digits", s))
+		}
+	}
+
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, jsonerror.New(4, "Invalid encoded string", err.Error())
+	}
+	return this.Decode(n), nil
+}
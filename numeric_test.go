@@ -0,0 +1,80 @@
+package optimus
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Tests values which fit exactly, need padding and overflow the length.
+func TestEncodeNumeric(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	//0 encodes to the random number, which has 10 digits
+	exact, err := o.EncodeNumeric(0, 10)
+	if err != nil || exact != "1163945558" {
+		t.Errorf("Expected 1163945558. Got %q (%v)", exact, err)
+	}
+
+	padded, err := o.EncodeNumeric(0, 16)
+	if err != nil || padded != "0000001163945558" {
+		t.Errorf("Expected 0000001163945558. Got %q (%v)", padded, err)
+	}
+
+	if s, err := o.EncodeNumeric(0, 9); err == nil {
+		t.Errorf("Expected 10 digits not to fit in 9. Got %q", s)
+	}
+
+	for _, s := range []string{exact, padded} {
+		n, err := o.DecodeNumeric(s, len(s))
+		if err != nil || n != 0 {
+			t.Errorf("%s -> %d (%v) - FAILED", s, n, err)
+		}
+	}
+
+	for _, value := range []uint64{1, 15, 1 << 63, MAX_INT} {
+		s, err := o.EncodeNumeric(value, NUMERIC_MAX_LEN)
+		if err != nil || len(s) != NUMERIC_MAX_LEN {
+			t.Errorf("%d: Unexpected %q (%v)", value, s, err)
+			continue
+		}
+
+		n, err := o.DecodeNumeric(s, NUMERIC_MAX_LEN)
+		if err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, s, n, err)
+		}
+	}
+
+	if _, err := o.EncodeNumeric(0, 0); err == nil {
+		t.Errorf("Expected a length of 0 to be rejected")
+	}
+}
+
+// Tests that malformed input is rejected.
+func TestDecodeNumericInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	max := strconv.FormatUint(MAX_INT, 10)
+
+	for _, bad := range []string{
+		"",
+		"116394555",
+		"11639455580",
+		"+163945558",
+		"-163945558",
+		" 163945558",
+		"11639455a8",
+	} {
+		if _, err := o.DecodeNumeric(bad, 10); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+
+	//One more than MAX_INT
+	overflow := max[:len(max)-1] + string(max[len(max)-1]+1)
+	if _, err := o.DecodeNumeric(overflow, NUMERIC_MAX_LEN); err == nil {
+		t.Errorf("Expected %s to be rejected", overflow)
+	}
+	if _, err := o.DecodeNumeric(strings.Repeat("9", NUMERIC_MAX_LEN), NUMERIC_MAX_LEN); err == nil {
+		t.Errorf("Expected 20 nines to be rejected")
+	}
+}
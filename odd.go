@@ -0,0 +1,35 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Returns the modular inverse of the odd number n modulo 2^64, such that
+// n * OddInverse(n) = 1 (mod 2^64). Unlike ModInverse, n does not need to be
+// prime. Returns an error if n is even since even numbers have no inverse.
+func OddInverse(n uint64) (uint64, error) {
+	if n&1 == 0 {
+		return 0, jsonerror.New(38, "Invalid multiplier", fmt.Sprintf("multiplier=%d. Must be odd", n))
+	}
+	return inverse64(n), nil
+}
+
+// Returns an Optimus which uses multiplier in place of the prime. Knuth's
+// hashing only requires the multiplier to be odd so that it has an inverse
+// modulo 2^64, which broadens the usable seeds. Returns an error if
+// multiplier is even or if the parameters yield the identity transform.
+// NB: The multiplier is still returned by Prime even though it need not be
+// prime.
+func NewOdd(multiplier uint64, random uint64) (Optimus, error) {
+	inverse, err := OddInverse(multiplier)
+	if err != nil {
+		return Optimus{}, err
+	}
+
+	if isIdentity(multiplier, inverse, random) {
+		return Optimus{}, ErrIdentityTransform
+	}
+
+	return Optimus{prime: multiplier, modInverse: inverse, random: random}, nil
+}
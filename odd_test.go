@@ -0,0 +1,57 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that odd composites have inverses and round-trip.
+func TestNewOdd(t *testing.T) {
+	multipliers := []uint64{
+		1580030175, //3 * 5^2 * 1451 * 14519
+		9,
+		15,
+		MAX_INT,
+		1580030173, //primes work too
+	}
+
+	for _, multiplier := range multipliers {
+		inverse, err := OddInverse(multiplier)
+		if err != nil || multiplier*inverse != 1 {
+			t.Errorf("OddInverse(%d) = %d (%v) is not an inverse - FAILED", multiplier, inverse, err)
+		}
+
+		o, err := NewOdd(multiplier, 1163945558)
+		if err != nil {
+			t.Errorf("%d - FAILED: %v", multiplier, err)
+			continue
+		}
+
+		for _, value := range []uint64{0, 1, 15, 1 << 63, MAX_INT} {
+			hashed := o.Encode(value)
+			unhashed := o.Decode(hashed)
+			if unhashed != value {
+				t.Errorf("%d: %d: %d -> %d - FAILED", multiplier, value, hashed, unhashed)
+			}
+		}
+	}
+
+	if inverse, _ := OddInverse(1580030173); inverse != ModInverse(1580030173) {
+		t.Errorf("Expected OddInverse to match ModInverse for primes")
+	}
+}
+
+// Tests that even multipliers and the identity are rejected.
+func TestNewOddInvalid(t *testing.T) {
+	for _, multiplier := range []uint64{0, 2, 1580030174} {
+		if _, err := OddInverse(multiplier); err == nil {
+			t.Errorf("Expected OddInverse(%d) to fail", multiplier)
+		}
+		if _, err := NewOdd(multiplier, 1163945558); err == nil {
+			t.Errorf("Expected NewOdd(%d) to fail", multiplier)
+		}
+	}
+
+	if _, err := NewOdd(1, 0); err != ErrIdentityTransform {
+		t.Errorf("Expected ErrIdentityTransform. Got %v", err)
+	}
+}
@@ -1,19 +1,10 @@
 package optimus
 
 import (
-	"archive/zip"
-	"bufio"
-	"bytes"
-	"crypto/rand"
 	"fmt"
 	"github.com/pjebs/jsonerror"
-	"io/ioutil"
-	"log"
 	"math"
 	"math/big"
-	"net/http"
-	"strconv"
-	"strings"
 )
 
 const (
@@ -21,20 +12,67 @@ const (
 	MILLER_RABIN = 20 //https://golang.org/pkg/math/big/#Int.ProbablyPrime
 )
 
+// Returned (or panicked) when the prime is 2. Knuth's hashing requires an odd
+// multiplier so that it has a modular inverse modulo 2^64.
+var ErrEvenPrime = jsonerror.New(8, "Prime is even", "2 has no modular inverse modulo 2^64")
+
+// Has the code of the error New, NewCalculated and ModInverse panic with
+// when prime is not prime. Match it using errors.Is on an error returned by
+// Try.
+var ErrNotPrime = jsonerror.New(2, "Number is not prime", "")
+
+// Returned (or panicked) when the parameters make Encode or Decode the
+// identity, which provides no obfuscation at all.
+var ErrIdentityTransform = jsonerror.New(24, "Identity transform", "prime or modInverse is 1 and random is 0")
+
+// Returned when the prime is not less than the modulus of a Modular or the
+// domain of an Optimus32. Optimus works modulo 2^64 so every uint64 prime
+// is below its modulus.
+var ErrPrimeTooLarge = jsonerror.New(35, "Prime too large", "The prime must be less than the modulus")
+
+// Returned by EncodeChecked and DecodeChecked (or panicked by Encode and
+// Decode in builds using the optimus_debug tag) when prime * modInverse is
+// not 1 modulo 2^64, ie. the seed would silently corrupt ids.
+var ErrInvalidSeed = jsonerror.New(41, "Invalid seed", "modInverse is not the inverse of prime modulo 2^64")
+
+// Mode selects how the random number is applied after the multiplication.
+type Mode uint8
+
+const (
+	// (n * prime) ^ random. The default. Matches jenssegers/optimus and
+	// earlier versions of this package.
+	MODE_XOR Mode = iota
+
+	// (n * prime + random) mod 2^64. Matches affine schemes which apply the
+	// random number as a modular offset.
+	MODE_ADDITIVE
+)
+
 type Optimus struct {
 	prime      uint64
 	modInverse uint64
 	random     uint64
+	mode       Mode
+	version    byte
 }
 
 // Returns an Optimus struct which can be used to encode and decode
 // integers. Usually used for obfuscating internal ids such as database
-// table rows. Panics if prime is not valid.
+// table rows. Panics if prime is not valid or is 2, or if the parameters
+// yield the identity transform.
 func New(prime uint64, modInverse uint64, random uint64) Optimus {
 
-	p := big.NewInt(int64(prime))
-	if p.ProbablyPrime(MILLER_RABIN) {
-		return Optimus{prime, modInverse, random}
+	if isIdentity(prime, modInverse, random) {
+		panic(ErrIdentityTransform)
+	}
+
+	if prime == 2 {
+		panic(ErrEvenPrime)
+	}
+
+	if isPrime(prime) {
+		warnInsecurePrime(prime)
+		return Optimus{prime: prime, modInverse: modInverse, random: random}
 	} else {
 		accuracy := 1.0 - 1.0/math.Pow(float64(4), float64(MILLER_RABIN))
 		panic(jsonerror.New(2, "Number is not prime", fmt.Sprintf("%d Miller-Rabin tests done. Accuracy: %f", MILLER_RABIN, accuracy)))
@@ -44,23 +82,105 @@ func New(prime uint64, modInverse uint64, random uint64) Optimus {
 
 // Returns an Optimus struct which can be used to encode and decode
 // integers. Usually used for obfuscating internal ids such as database
-// table rows. This method calculates the modInverse computationally.
-// Panics if prime is not valid.
+// table rows. This method calculates the modInverse computationally,
+// memoizing it if CacheModInverses is enabled.
+// Panics if prime is not valid or is 2, or if the parameters yield the
+// identity transform.
 func NewCalculated(prime uint64, random uint64) Optimus {
-	p := big.NewInt(int64(prime))
-	if p.ProbablyPrime(MILLER_RABIN) {
-		return Optimus{prime, ModInverse(prime), random}
+	if isIdentity(prime, prime, random) {
+		panic(ErrIdentityTransform)
+	}
+
+	if prime == 2 {
+		panic(ErrEvenPrime)
+	}
+
+	if inverse, ok := cachedModInverse(prime); ok {
+		warnInsecurePrime(prime)
+		return Optimus{prime: prime, modInverse: inverse, random: random}
+	}
+
+	if isPrime(prime) {
+		warnInsecurePrime(prime)
+		inverse := ModInverse(prime)
+		storeModInverse(prime, inverse)
+		return Optimus{prime: prime, modInverse: inverse, random: random}
 	} else {
 		accuracy := 1.0 - 1.0/math.Pow(float64(4), float64(MILLER_RABIN))
 		panic(jsonerror.New(2, "Number is not prime", fmt.Sprintf("%d Miller-Rabin tests done. Accuracy: %f", MILLER_RABIN, accuracy)))
 	}
 }
 
+// Returns an Optimus struct without validating anything: the prime is not
+// tested for primality and modInverse is not checked against it. It is
+// intended for tests and benchmarks which construct many Optimus structs
+// and don't want to pay for Miller-Rabin.
+// WARNING: DO NOT USE IN PRODUCTION. Invalid parameters silently produce
+// ids which do not decode. Use New or NewCalculated instead.
+func NewUnchecked(prime uint64, modInverse uint64, random uint64) Optimus {
+	return Optimus{prime: prime, modInverse: modInverse, random: random}
+}
+
+// Returns an Optimus struct like NewCalculated but runs as many Miller-Rabin
+// rounds as are required for the probability that prime is actually prime
+// to be at least minAccuracy. Each round has an accuracy of 3/4 so n rounds
+// give 1 - 4^-n. Returns an error if minAccuracy is not in [0, 1), if prime
+// is 2, if prime is not prime or if the parameters yield the identity. The
+// prime must also pass PrimalityTest.
+// NB: float64 can not represent accuracies above 1 - 2^-53 (27 rounds).
+// 1 - 2^-128 rounds to 1.0 and is rejected. Use a round count instead.
+func NewWithMinAccuracy(prime uint64, random uint64, minAccuracy float64) (Optimus, error) {
+	rounds, err := roundsForAccuracy(minAccuracy)
+	if err != nil {
+		return Optimus{}, err
+	}
+
+	if isIdentity(prime, prime, random) {
+		return Optimus{}, ErrIdentityTransform
+	}
+
+	if prime == 2 {
+		return Optimus{}, ErrEvenPrime
+	}
+
+	if !isPrime(prime) || !new(big.Int).SetUint64(prime).ProbablyPrime(rounds) {
+		accuracy := 1.0 - 1.0/math.Pow(float64(4), float64(rounds))
+		return Optimus{}, jsonerror.New(2, "Number is not prime", fmt.Sprintf("n=%d. %d Miller-Rabin tests done. Accuracy: %f", prime, rounds, accuracy))
+	}
+
+	warnInsecurePrime(prime)
+
+	return Optimus{prime: prime, modInverse: ModInverse(prime), random: random}, nil
+}
+
+// Reports whether Encode (prime is 1) or Decode (modInverse is 1) would
+// return its input unchanged.
+func isIdentity(prime uint64, modInverse uint64, random uint64) bool {
+	return random == 0 && (prime == 1 || modInverse == 1)
+}
+
+// Returns the smallest number of Miller-Rabin rounds n such that
+// 1 - 4^-n >= minAccuracy.
+func roundsForAccuracy(minAccuracy float64) (int, error) {
+	if !(minAccuracy >= 0 && minAccuracy < 1) {
+		return 0, jsonerror.New(23, "Invalid accuracy", fmt.Sprintf("minAccuracy=%v. Must be at least 0 and less than 1", minAccuracy))
+	}
+	return int(math.Ceil(-math.Log2(1-minAccuracy) / 2)), nil
+}
+
 // Encodes n using Knuth's Hashing Algorithm.
 // Ensure that you store the prime, modInverse and random number
 // associated with the Optimus struct so that it can be decoded
 // correctly.
+// NB: 0 is a valid id and encodes to the random number. If 0 means "no id"
+// in your system, use EncodeNonZero so that it is never obfuscated.
 func (this Optimus) Encode(n uint64) uint64 {
+	if debug {
+		assertSeed(this.prime, this.modInverse)
+	}
+	if this.mode == MODE_ADDITIVE {
+		return ((n * this.prime) + this.random) & MAX_INT
+	}
 	return ((n * this.prime) & MAX_INT) ^ this.random
 }
 
@@ -69,9 +189,68 @@ func (this Optimus) Encode(n uint64) uint64 {
 // number associated with the Optimus struct is consistent with when the number
 // was originally hashed.
 func (this Optimus) Decode(n uint64) uint64 {
+	if debug {
+		assertSeed(this.prime, this.modInverse)
+	}
+	if this.mode == MODE_ADDITIVE {
+		return ((n - this.random) * this.modInverse) & MAX_INT
+	}
 	return ((n ^ this.random) * this.modInverse) & MAX_INT
 }
 
+// Encodes n like Encode but first checks that the modInverse is consistent
+// with the prime. Returns ErrInvalidSeed if it is not. The check is a single
+// multiplication.
+func (this Optimus) EncodeChecked(n uint64) (uint64, error) {
+	if this.prime*this.modInverse != 1 {
+		return 0, ErrInvalidSeed
+	}
+	return this.Encode(n), nil
+}
+
+// Decodes n like Decode but first checks that the modInverse is consistent
+// with the prime. Returns ErrInvalidSeed if it is not.
+func (this Optimus) DecodeChecked(n uint64) (uint64, error) {
+	if this.prime*this.modInverse != 1 {
+		return 0, ErrInvalidSeed
+	}
+	return this.Decode(n), nil
+}
+
+// Decodes n and reports whether n is a canonical in-domain encoding, i.e.
+// encoding the decoded value gives back n. Every uint64 is inside the
+// 2^64 domain so ok is only false if the modInverse is not consistent
+// with the prime.
+func (this Optimus) DecodeOK(n uint64) (uint64, bool) {
+	decoded := this.Decode(n)
+	return decoded, this.Encode(decoded) == n
+}
+
+// Returns a copy of the Optimus which applies the random number using mode.
+// Numbers encoded with one mode can only be decoded with the same mode.
+func (this Optimus) WithMode(mode Mode) Optimus {
+	this.mode = mode
+	return this
+}
+
+// Returns the Associated Mode.
+func (this Optimus) Mode() Mode {
+	return this.mode
+}
+
+// Returns a copy of the Optimus labelled with version, eg. to tell seeds
+// apart after rotation. The version does not affect encoding and is not
+// stored by EncryptSeed or FormatSeed. See EncodeFull.
+func (this Optimus) WithVersion(version byte) Optimus {
+	this.version = version
+	return this
+}
+
+// Returns the Associated Version.
+func (this Optimus) Version() byte {
+	return this.version
+}
+
 // Returns the Associated Prime Number. DO NOT DEVULGE THIS NUMBER!
 func (this Optimus) Prime() uint64 {
 	return this.prime
@@ -89,153 +268,23 @@ func (this Optimus) Random() uint64 {
 
 // Calculates the Modular Inverse of a given Prime number such that
 // (PRIME * MODULAR_INVERSE) & (MAX_INT_VALUE) = 1
-// Panics if n is not a valid prime number.
+// Panics if n is not a valid prime number or is 2.
 // See: http://en.wikipedia.org/wiki/Modular_multiplicative_inverse
 func ModInverse(n uint64) uint64 {
 
-	p := big.NewInt(int64(n))
-	if !p.ProbablyPrime(MILLER_RABIN) {
+	if n == 2 {
+		panic(ErrEvenPrime)
+	}
+
+	if !isPrime(n) {
 		accuracy := 1.0 - 1.0/math.Pow(float64(4), float64(MILLER_RABIN))
 		panic(jsonerror.New(2, "Number is not prime", fmt.Sprintf("n=%d. %d Miller-Rabin tests done. Accuracy: %f", n, MILLER_RABIN, accuracy)))
 	}
 
 	var i big.Int
 
-	prime := big.NewInt(int64(n))
-	max := big.NewInt(int64(MAX_INT + 1))
+	prime := new(big.Int).SetUint64(n)
+	max := new(big.Int).Lsh(big.NewInt(1), 64)
 
 	return i.ModInverse(prime, max).Uint64()
 }
-
-// Generates a valid Optimus struct using a randomly selected prime
-// number from this site: http://primes.utm.edu/lists/small/millions/
-// The first 50 million prime numbers are distributed evenly in 50 files.
-// Parameter req should be nil if not using Google App Engine.
-// This Function is Time, Memory and CPU intensive. Run it once to generate the
-// required seeds.
-// WARNING: Potentially Insecure. Double check that the prime number returned
-// is actually prime number using an independent source.
-// The largest Prime has 9 digits. The smallest has 1 digit.
-// The final return value is the website zip file identifier that was used to obtain the prime number
-func GenerateSeed(req *http.Request) (*Optimus, error, uint8) {
-	log.Printf("\x1b[31mWARNING: Optimus generates a random number via this site: http://primes.utm.edu/lists/small/millions/. This is potentially insecure!\x1b[39;49m")
-
-	baseURL := "http://primes.utm.edu/lists/small/millions/primes%d.zip"
-
-	//Generate Random number between 1-50
-	b_49 := *big.NewInt(49)
-	n, _ := rand.Int(rand.Reader, &b_49)
-	i_n := n.Uint64() + 1
-
-	//Download zip file
-	finalUrl := fmt.Sprintf(baseURL, i_n)
-	log.Printf("Using file: %s", finalUrl)
-
-	resp, err := client(req).Get(finalUrl)
-	if err != nil {
-		return nil, jsonerror.New(1, "Could not generate seed", err.Error()), uint8(i_n)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, jsonerror.New(1, "Could not generate seed", err.Error()), uint8(i_n)
-	}
-
-	r, err := zip.NewReader(bytes.NewReader(body), resp.ContentLength)
-	if err != nil {
-		return nil, jsonerror.New(1, "Could not generate seed", err.Error()), uint8(i_n)
-	}
-
-	zippedFile := r.File[0]
-
-	src, err := zippedFile.Open() //src contains ReaderCloser
-	if err != nil {
-		return nil, jsonerror.New(1, "Could not generate seed", err.Error()), uint8(i_n)
-	}
-	defer src.Close()
-
-	//Create a Byte Slice
-	buf := new(bytes.Buffer)
-	noOfBytes, _ := buf.ReadFrom(src)
-	b := buf.Bytes() //Byte Slice
-
-	//Randomly pick a character position
-	start := 67 // Each zip file has an introductory header which is not relevant until the 67th character
-	end := noOfBytes
-
-	b_end := *big.NewInt(int64(end) - int64(start))
-	n, _ = rand.Int(rand.Reader, &b_end)
-	randomPosition := n.Uint64() + uint64(start)
-
-	min := randomPosition - 9
-	max := randomPosition + 9
-
-	if min < uint64(start) {
-		min = uint64(start)
-	}
-
-	if max > uint64(end) {
-		max = uint64(end)
-	}
-
-	scanner := bufio.NewScanner(strings.NewReader(string(b[min:max]))) //Input
-	scanner.Split(bufio.ScanWords)
-
-	var selectedNumbers []uint64
-	for scanner.Scan() {
-		p, _ := strconv.ParseUint(scanner.Text(), 10, 64)
-		selectedNumbers = append(selectedNumbers, p)
-	}
-
-	//Not perfect but good enough
-
-	var selectedPrime uint64
-	length := len(selectedNumbers)
-	if length > 2 {
-		//Pick middle number
-
-		//Check if length is even number
-		//Check if round is odd or even
-		var odd bool
-		if length&1 != 0 {
-			odd = true //odd
-		} else {
-			odd = false //even
-		}
-
-		if odd {
-			selectedPrime = selectedNumbers[length/2]
-		} else {
-
-			r := *big.NewInt(1)
-			rn, _ := rand.Int(rand.Reader, &r)
-			if rn.Uint64() == 0 {
-				selectedPrime = selectedNumbers[length/2]
-			} else {
-				selectedPrime = selectedNumbers[length/2-1]
-			}
-		}
-	} else {
-		//Pick largest number
-		largest := selectedNumbers[0]
-
-		for _, value := range selectedNumbers {
-			if value > largest {
-				largest = value
-			}
-		}
-
-		selectedPrime = largest
-	}
-
-	//Calculate Mod Inverse for selectedPrime
-	modInverse := ModInverse(selectedPrime)
-
-	//Generate Random Integer less than MAX_INT
-	upper := *big.NewInt(MAX_INT - 2)
-	rand, _ := rand.Int(rand.Reader, &upper)
-	randomNumber := rand.Uint64() + 1
-
-	return &Optimus{selectedPrime, modInverse, randomNumber}, nil, uint8(i_n)
-}
@@ -0,0 +1,104 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+const (
+	MAX_INT_31 = 1<<31 - 1 // Largest id in the 31-bit domain
+	MAX_INT_32 = 1<<32 - 1 // Largest id in the 32-bit domain
+)
+
+// Optimus32 is a variant of Optimus for legacy 32-bit ids which works
+// entirely with uint32 values in a 31-bit or 32-bit domain.
+type Optimus32 struct {
+	prime      uint32
+	modInverse uint32
+	random     uint32
+	mask       uint32
+}
+
+// Returns an Optimus32 working in a domain of bits (31 or 32) bits. The
+// 31-bit domain matches the original PHP library. Returns an error if the
+// prime is not valid, modInverse is not its inverse modulo 2^bits or
+// random is outside the domain. Returns ErrPrimeTooLarge if the prime is
+// outside the domain.
+func NewOptimus32(prime uint32, modInverse uint32, random uint32, bits uint8) (Optimus32, error) {
+	var mask uint32
+	switch bits {
+	case BITS_31:
+		mask = MAX_INT_31
+	case BITS_32:
+		mask = MAX_INT_32
+	default:
+		return Optimus32{}, jsonerror.New(14, "Invalid bit width", fmt.Sprintf("bits=%d. Must be 31 or 32", bits))
+	}
+
+	if prime > mask {
+		return Optimus32{}, ErrPrimeTooLarge
+	}
+
+	if err := validatePrime(uint64(prime)); err != nil {
+		return Optimus32{}, err
+	}
+
+	if (prime*modInverse)&mask != 1 {
+		return Optimus32{}, jsonerror.New(15, "Invalid modInverse", fmt.Sprintf("%d is not the inverse of %d modulo 2^%d", modInverse, prime, bits))
+	}
+
+	if random > mask {
+		return Optimus32{}, jsonerror.New(16, "Invalid random", fmt.Sprintf("random=%d. Must not exceed %d", random, mask))
+	}
+
+	return Optimus32{prime, modInverse & mask, random, mask}, nil
+}
+
+// Returns an Optimus32 for a prime, calculating the modInverse. See
+// NewOptimus32.
+func NewOptimus32Calculated(prime uint32, random uint32, bits uint8) (Optimus32, error) {
+	//Newton's iteration doubles the number of correct low bits each round
+	inverse := prime
+	for i := 0; i < 5; i++ {
+		inverse *= 2 - prime*inverse
+	}
+	return NewOptimus32(prime, inverse, random, bits)
+}
+
+// Encodes n using Knuth's Hashing Algorithm. In the 31-bit domain the top
+// bit of n is ignored. Builds using the optimus_debug tag panic instead.
+func (this Optimus32) Encode32(n uint32) uint32 {
+	if debug {
+		assertInDomain("Encode32", uint64(n), uint64(this.mask))
+	}
+	return ((n * this.prime) & this.mask) ^ this.random
+}
+
+// Decodes a number produced by Encode32. Builds using the optimus_debug tag
+// panic if n is outside the domain.
+func (this Optimus32) Decode32(n uint32) uint32 {
+	if debug {
+		assertInDomain("Decode32", uint64(n), uint64(this.mask))
+	}
+	return ((n ^ this.random) * this.modInverse) & this.mask
+}
+
+// Returns the Associated Prime Number. DO NOT DEVULGE THIS NUMBER!
+func (this Optimus32) Prime() uint32 {
+	return this.prime
+}
+
+// Returns the Associated ModInverse Number. DO NOT DEVULGE THIS NUMBER!
+func (this Optimus32) ModInverse() uint32 {
+	return this.modInverse
+}
+
+// Returns the Associated Random Number. DO NOT DEVULGE THIS NUMBER!
+func (this Optimus32) Random() uint32 {
+	return this.random
+}
+
+// Returns the largest id in the domain.
+func (this Optimus32) Max() uint32 {
+	return this.mask
+}
@@ -0,0 +1,78 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests the 31-bit domain using the seed from the README.
+func TestOptimus32Readme(t *testing.T) {
+	o, err := NewOptimus32(1580030173, 59260789, 1163945558, 31)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := o.Encode32(15); got != 1103647397 {
+		t.Errorf("Expected 15 to encode to 1103647397. Got %d", got)
+	}
+
+	if got := o.Decode32(1103647397); got != 15 {
+		t.Errorf("Expected 1103647397 to decode to 15. Got %d", got)
+	}
+}
+
+// Tests round-trips across a sample spread evenly over both domains.
+func TestOptimus32Sample(t *testing.T) {
+	for _, bits := range []uint8{31, 32} {
+		o, err := NewOptimus32Calculated(1580030173, 1163945558, bits)
+		if err != nil {
+			t.Errorf("%d bits - FAILED: %v", bits, err)
+			continue
+		}
+
+		max := uint64(o.Max())
+		for n := uint64(0); n <= max; n += 65521 {
+			check32(t, o, uint32(n))
+		}
+		for n := max - 100; n <= max; n++ {
+			check32(t, o, uint32(n))
+		}
+	}
+}
+
+func check32(t *testing.T, o Optimus32, n uint32) {
+	encoded := o.Encode32(n)
+	if encoded > o.Max() {
+		t.Errorf("%d encoded to %d which is outside the domain", n, encoded)
+	}
+	if decoded := o.Decode32(encoded); decoded != n {
+		t.Errorf("%d: %d -> %d - FAILED", n, encoded, decoded)
+	}
+}
+
+// Tests that invalid parameters are rejected.
+func TestOptimus32Invalid(t *testing.T) {
+	tests := []struct {
+		prime, modInverse, random uint32
+		bits                      uint8
+	}{
+		{1580030173, 59260789, 1163945558, 16},         //unsupported width
+		{1580030175, 59260789, 1163945558, 31},         //not prime
+		{1580030173, 59260788, 1163945558, 31},         //wrong inverse
+		{1580030173, 59260789, 1 << 31, 31},            //random outside 31 bits
+		{1580030173, 59260789 + 1<<31, 1163945558, 32}, //inverse only valid for 31 bits
+	}
+
+	for _, test := range tests {
+		if _, err := NewOptimus32(test.prime, test.modInverse, test.random, test.bits); err == nil {
+			t.Errorf("Expected %v to be rejected", test)
+		}
+	}
+
+	//4294967291 is the largest 32-bit prime and is outside the 31-bit domain
+	if _, err := NewOptimus32Calculated(4294967291, 1163945558, 31); err != ErrPrimeTooLarge {
+		t.Errorf("Expected ErrPrimeTooLarge. Got %v", err)
+	}
+	if _, err := NewOptimus32Calculated(4294967291, 1163945558, 32); err != nil {
+		t.Errorf("Expected 4294967291 to be accepted in the 32-bit domain. Got %v", err)
+	}
+}
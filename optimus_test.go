@@ -3,127 +3,269 @@
 package optimus
 
 import (
-	"archive/zip"
-	"bytes"
-	"crypto/rand"
-	"fmt"
-	"io/ioutil"
-	// "log"
-	"math/big"
-	"strings"
+	"math"
 	"testing"
-	"unsafe"
 )
 
-// Obtains a prime number from the internet, calculates the mod inverse of it and
-// calculates a random number. It then checks if the process worked BUT does not
-// test if the number obtained is actually Prime.
-func TestGenerateSeed(t *testing.T) {
+// Regression cases for bugs in the arithmetic. Each case documents the bug
+// it guards against so it stays covered even when fuzzing is not run.
+func TestRegressionCases(t *testing.T) {
 
-	for i := 0; i < 3; i++ { //How many times we want to run GenerateSeed()
-		o, err, f := GenerateSeed(nil)
-		if err != nil {
-			t.Errorf("Try %d - Failed", i)
+	//ModInverse must build the modulus 2^64 and the prime without going
+	//through int64, which can not hold 2^64 or primes of 2^63 and above.
+	//The inverse is verified modulo 2^64 using wrapping uint64
+	//multiplication.
+	inverseCases := []struct {
+		prime uint64
+		bug   string
+	}{
+		{3, "smallest odd prime"},
+		{1580030173, "README example prime"},
+		{2147483647, "largest 31-bit prime (old MAXID)"},
+		{4294967291, "largest 32-bit prime"},
+		{9223372036854775783, "largest prime below 2^63"},
+		{18446744073709551557, "largest 64-bit prime, negative when truncated to int64"},
+	}
+
+	for _, c := range inverseCases {
+		inverse := ModInverse(c.prime)
+		if c.prime*inverse != 1 {
+			t.Errorf("ModInverse(%d) = %d is not an inverse modulo 2^64 (%s) - FAILED", c.prime, inverse, c.bug)
 		}
+	}
 
-		//Check if prime is contained in zipped text file
-		baseURL := "http://primes.utm.edu/lists/small/millions/primes%d.zip"
-		finalUrl := fmt.Sprintf(baseURL, f)
+	//Encode masks with MAX_INT. Values either side of the 31-bit and 32-bit
+	//boundaries and at the top of the uint64 range must survive a round-trip.
+	roundTripCases := []struct {
+		prime  uint64
+		random uint64
+		n      uint64
+		bug    string
+	}{
+		{1580030173, 1163945558, 0, "zero encodes to random"},
+		{1580030173, 1163945558, 2147483647, "old MAXID"},
+		{1580030173, 1163945558, 2147483648, "first value above old MAXID"},
+		{1580030173, 1163945558, 1 << 32, "first value above 32 bits"},
+		{1580030173, 1163945558, 1 << 63, "sign bit of int64"},
+		{1580030173, 1163945558, MAX_INT, "MAX_INT"},
+		{9223372036854775783, MAX_INT, MAX_INT - 1, "large prime and random with n*prime overflowing"},
+	}
 
-		resp, err := client(nil).Get(finalUrl)
-		if err != nil {
-			t.Errorf("Try %d - Failed", i)
-			continue
+	for _, c := range roundTripCases {
+		o := NewCalculated(c.prime, c.random)
+		hashed := o.Encode(c.n)
+		unhashed := o.Decode(hashed)
+		if unhashed != c.n {
+			t.Errorf("%d: %d -> %d (%s) - FAILED", c.n, hashed, unhashed, c.bug)
 		}
-		defer resp.Body.Close()
+	}
+}
 
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			t.Errorf("Try %d - Failed", i)
-			continue
+// Tests that DecodeOK accepts valid encodings and flags values which are
+// not canonical for an inconsistent seed.
+func TestDecodeOK(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	for _, value := range []uint64{0, 15, 1 << 63, MAX_INT} {
+		n, ok := o.DecodeOK(o.Encode(value))
+		if !ok || n != value {
+			t.Errorf("%d: -> %d (%t) - FAILED", value, n, ok)
 		}
+	}
 
-		r, err := zip.NewReader(bytes.NewReader(body), resp.ContentLength)
-		if err != nil {
-			t.Errorf("Try %d - Failed", i)
-			continue
-		}
+	//Debug builds panic on inconsistent seeds instead
+	if debug {
+		return
+	}
 
-		zippedFile := r.File[0]
+	//59260789 is the inverse of 1580030173 modulo 2^31, not 2^64
+	bad := New(1580030173, 59260789, 1163945558)
+	if _, ok := bad.DecodeOK(bad.Encode(15)); ok {
+		t.Errorf("Expected inconsistent seed to be reported")
+	}
+}
 
-		src, err := zippedFile.Open() //src contains ReaderCloser
-		if err != nil {
-			t.Errorf("Try %d - Failed", i)
-			continue
-		}
-		// defer src.Close()
-
-		//Create a Byte Slice
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(src)
-		b := buf.Bytes()
-		stringContents := *(*string)(unsafe.Pointer(&b))
-
-		subString := fmt.Sprintf(" %d ", o.Prime())
-		if !strings.Contains(stringContents, subString) {
-			src.Close()
-			t.Errorf("Try %d - Failed - Obtained Prime:%d is not a Prime", i, o.Prime())
-			continue
-		}
+// Tests that EncodeChecked and DecodeChecked reject an inconsistent seed
+// and otherwise match Encode and Decode.
+func TestChecked(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	encoded, err := o.EncodeChecked(15)
+	if err != nil || encoded != o.Encode(15) {
+		t.Errorf("Expected %d. Got %d (%v)", o.Encode(15), encoded, err)
+	}
+	if decoded, err := o.DecodeChecked(encoded); err != nil || decoded != 15 {
+		t.Errorf("Expected 15. Got %d (%v)", decoded, err)
+	}
 
-		src.Close()
+	//59260789 is the inverse of 1580030173 modulo 2^31, not 2^64
+	bad := New(1580030173, 59260789, 1163945558)
+	if _, err := bad.EncodeChecked(15); err != ErrInvalidSeed {
+		t.Errorf("Expected ErrInvalidSeed. Got %v", err)
+	}
+	if _, err := bad.DecodeChecked(15); err != ErrInvalidSeed {
+		t.Errorf("Expected ErrInvalidSeed. Got %v", err)
+	}
+}
 
-		//Check if ModInverse is correct
+// Tests that the even prime 2 is rejected since it has no modular inverse.
+func TestEvenPrime(t *testing.T) {
+	constructors := map[string]func(){
+		"New":           func() { New(2, 1, 1163945558) },
+		"NewCalculated": func() { NewCalculated(2, 1163945558) },
+		"ModInverse":    func() { ModInverse(2) },
+	}
 
-		// if o.Prime() != ModInverse(o.ModInverse()) {
-		// 	src.Close()
-		// 	t.Errorf("Try %d - Failed - ModInverse(%d) of %d is not correct", i, o.ModInverse, o.Prime)
-		// 	continue
-		// }
+	for name, f := range constructors {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrEvenPrime {
+					t.Errorf("Expected %s(2) to panic with ErrEvenPrime. Got %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
 
+	if _, err := DeriveSeed(2); err != ErrEvenPrime {
+		t.Errorf("Expected DeriveSeed(2) to return ErrEvenPrime. Got %v", err)
 	}
 }
 
-// Tests if the encoding process correctly decodes the id back to the original.
-func TestEncoding(t *testing.T) {
-	for i := 0; i < 15; i++ { //How many times we want to run GenerateSeed()
-		o, _, _ := GenerateSeed(nil)
+// Tests that both ways of applying the random number round-trip and that
+// the additive mode computes (n * prime + random) mod 2^64.
+func TestModes(t *testing.T) {
+	xor := NewCalculated(1580030173, 1163945558)
+	additive := xor.WithMode(MODE_ADDITIVE)
 
-		c := 10
-		h := 100 //How many random numbers to select in between 0-c and (MAX_INT-c) - MAX-INT
+	if xor.Mode() != MODE_XOR || additive.Mode() != MODE_ADDITIVE {
+		t.Fatalf("Unexpected modes %d and %d", xor.Mode(), additive.Mode())
+	}
 
-		var y []uint64 //Stores all the values we want to run encoding tests on
+	if got := additive.Encode(15); got != 15*1580030173+1163945558 {
+		t.Errorf("Expected additive Encode(15) to be %d. Got %d", uint64(15*1580030173+1163945558), got)
+	}
 
-		for t := 0; t < c; t++ {
-			y = append(y, uint64(t))
+	for _, o := range []Optimus{xor, additive} {
+		for _, value := range []uint64{0, 1, 15, 1 << 63, MAX_INT - 1163945557, MAX_INT} {
+			hashed := o.Encode(value)
+			unhashed := o.Decode(hashed)
+			if unhashed != value {
+				t.Errorf("Mode %d: %d: %d -> %d - FAILED", o.Mode(), value, hashed, unhashed)
+			}
 		}
+	}
 
-		//Generate Random numbers
-		for t := 0; t < h; t++ {
-			upper := *big.NewInt(int64(MAX_INT - 2*c))
-			rand, _ := rand.Int(rand.Reader, &upper)
-			randomNumber := rand.Uint64() + uint64(c)
+	if xor.Encode(15) == additive.Encode(15) {
+		t.Errorf("Expected the modes to produce different outputs")
+	}
+}
 
-			y = append(y, randomNumber)
-		}
+// Tests that requested accuracies map to the minimum number of rounds.
+func TestRoundsForAccuracy(t *testing.T) {
+	tests := []struct {
+		accuracy float64
+		rounds   int
+	}{
+		{0, 0},
+		{0.5, 1},
+		{0.75, 1},
+		{0.76, 2},
+		{0.99, 4},
+		{1 - 1.0/(1<<20), 10},
+		{1 - 1.0/(1<<40), 20},
+		{1 - 1.0/(1<<40) - 1.0/(1<<52), 20},
+		{1 - 1.0/(1<<40) + 1.0/(1<<52), 21},
+		{1 - 1.0/(1<<53), 27},
+	}
 
-		for t := MAX_INT; t >= MAX_INT-c; t-- {
-			y = append(y, uint64(t))
+	for _, test := range tests {
+		rounds, err := roundsForAccuracy(test.accuracy)
+		if err != nil || rounds != test.rounds {
+			t.Errorf("%v: Expected %d rounds. Got %d (%v) - FAILED", test.accuracy, test.rounds, rounds, err)
 		}
+	}
+}
 
-		t.Logf("Prime: %d ModInverse: %d Random: %d", o.Prime(), o.ModInverse(), o.Random())
-		for _, value := range y {
-			orig := value
-			hashed := o.Encode(value)
-			unhashed := o.Decode(hashed)
+// Tests that NewWithMinAccuracy validates the prime and the accuracy.
+func TestNewWithMinAccuracy(t *testing.T) {
+	o, err := NewWithMinAccuracy(1580030173, 1163945558, 0.999999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o != NewCalculated(1580030173, 1163945558) {
+		t.Errorf("Unexpected Optimus %v", o)
+	}
 
-			if orig != unhashed {
-				t.Errorf("%d: %d -> %d - FAILED", orig, hashed, unhashed)
-			} else {
-				t.Logf("%d: %d -> %d - PASSED", orig, hashed, unhashed)
-				// log.Printf("%d: %d -> %d - PASSED", orig, hashed, unhashed)
-			}
+	if _, err := NewWithMinAccuracy(1580030175, 1163945558, 0.999999); err == nil {
+		t.Errorf("Expected composite to be rejected")
+	}
+
+	if _, err := NewWithMinAccuracy(2, 1163945558, 0.5); err != ErrEvenPrime {
+		t.Errorf("Expected ErrEvenPrime. Got %v", err)
+	}
+
+	for _, accuracy := range []float64{1, 1 - 1.0/(1<<60), 1.5, -0.1, math.NaN()} {
+		if _, err := NewWithMinAccuracy(1580030173, 1163945558, accuracy); err == nil {
+			t.Errorf("Expected accuracy %v to be rejected", accuracy)
 		}
+	}
+}
+
+// Tests that seeds which yield the identity transform are rejected.
+func TestIdentityTransform(t *testing.T) {
+	constructors := map[string]func(){
+		"New(1, 1, 0)":        func() { New(1, 1, 0) },
+		"New(prime, 1, 0)":    func() { New(1580030173, 1, 0) },
+		"NewCalculated(1, 0)": func() { NewCalculated(1, 0) },
+	}
+
+	for name, f := range constructors {
+		func() {
+			defer func() {
+				if r := recover(); r != ErrIdentityTransform {
+					t.Errorf("Expected %s to panic with ErrIdentityTransform. Got %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+
+	if _, err := NewWithMinAccuracy(1, 0, 0.5); err != ErrIdentityTransform {
+		t.Errorf("Expected NewWithMinAccuracy to return ErrIdentityTransform. Got %v", err)
+	}
+
+	//A zero random is fine on its own
+	o := NewCalculated(1580030173, 0)
+	if o.Encode(15) == 15 {
+		t.Errorf("Expected 15 to be obfuscated")
+	}
+}
+
+// Tests that NewUnchecked matches New for valid parameters and skips
+// validation.
+func TestNewUnchecked(t *testing.T) {
+	if o := NewUnchecked(1580030173, 59260789, 1163945558); o != New(1580030173, 59260789, 1163945558) {
+		t.Errorf("Expected NewUnchecked to match New. Got %v", o)
+	}
+
+	//A composite does not panic
+	o := NewUnchecked(1580030175, 0, 1163945558)
+	if o.Prime() != 1580030175 {
+		t.Errorf("Expected the prime to be kept. Got %d", o.Prime())
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	inverse := ModInverse(9223372036854775783)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink += New(9223372036854775783, inverse, 1163945558).Prime()
+	}
+}
 
+func BenchmarkNewUnchecked(b *testing.B) {
+	inverse := ModInverse(9223372036854775783)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink += NewUnchecked(9223372036854775783, inverse, 1163945558).Prime()
 	}
 }
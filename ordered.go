@@ -0,0 +1,49 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// OrderPreservingObfuscator encodes n as n*stride + offset without wrapping,
+// so larger ids always encode to larger values and obfuscated ids sort in
+// insertion order.
+// WARNING: This provides far weaker obfuscation than Optimus. Two encoded
+// ids reveal the stride and offset, and the gap between any two encoded ids
+// reveals how many ids lie between them. Only use it when sorting matters
+// more than hiding the ids.
+type OrderPreservingObfuscator struct {
+	stride uint64
+	offset uint64
+	max    uint64 // Largest id which does not overflow
+}
+
+// Returns an OrderPreservingObfuscator. Returns an error if stride is 0.
+func NewOrderPreservingObfuscator(stride uint64, offset uint64) (OrderPreservingObfuscator, error) {
+	if stride == 0 {
+		return OrderPreservingObfuscator{}, jsonerror.New(30, "Invalid stride", "Stride must be positive")
+	}
+	return OrderPreservingObfuscator{stride, offset, (MAX_INT - offset) / stride}, nil
+}
+
+// Encodes n as n*stride + offset. Returns an error if n is larger than Max.
+func (this OrderPreservingObfuscator) Encode(n uint64) (uint64, error) {
+	if n > this.max {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("n=%d. Must not exceed %d", n, this.max))
+	}
+	return n*this.stride + this.offset, nil
+}
+
+// Decodes a number produced by Encode. Returns an error if n is not a
+// possible encoding.
+func (this OrderPreservingObfuscator) Decode(n uint64) (uint64, error) {
+	if n < this.offset || (n-this.offset)%this.stride != 0 {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("%d is not an encoding", n))
+	}
+	return (n - this.offset) / this.stride, nil
+}
+
+// Returns the largest id which can be encoded.
+func (this OrderPreservingObfuscator) Max() uint64 {
+	return this.max
+}
@@ -0,0 +1,53 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that encoding is monotone and reversible.
+func TestOrderPreservingObfuscator(t *testing.T) {
+	o, err := NewOrderPreservingObfuscator(1580030173, 1163945558)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := []uint64{0, 1, 2, 15, 1000, 1 << 20, 1 << 32, o.Max() - 1, o.Max()}
+
+	var previous uint64
+	for i, value := range values {
+		encoded, err := o.Encode(value)
+		if err != nil {
+			t.Fatalf("%d - FAILED: %v", value, err)
+		}
+
+		if i > 0 && encoded <= previous {
+			t.Errorf("%d: Expected %d to be larger than %d", value, encoded, previous)
+		}
+		previous = encoded
+
+		decoded, err := o.Decode(encoded)
+		if err != nil || decoded != value {
+			t.Errorf("%d: %d -> %d (%v) - FAILED", value, encoded, decoded, err)
+		}
+	}
+}
+
+// Tests that ids past Max and values which are not encodings are rejected.
+func TestOrderPreservingObfuscatorInvalid(t *testing.T) {
+	o, _ := NewOrderPreservingObfuscator(1580030173, 1163945558)
+
+	if _, err := o.Encode(o.Max() + 1); err == nil {
+		t.Errorf("Expected %d to be rejected", o.Max()+1)
+	}
+
+	encoded, _ := o.Encode(15)
+	for _, n := range []uint64{0, 1163945557, encoded + 1, encoded - 1} {
+		if _, err := o.Decode(n); err == nil {
+			t.Errorf("Expected %d to be rejected", n)
+		}
+	}
+
+	if _, err := NewOrderPreservingObfuscator(0, 1); err == nil {
+		t.Errorf("Expected stride 0 to be rejected")
+	}
+}
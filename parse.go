@@ -0,0 +1,109 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Matches an optionally keyed number such as `random=5`, `"prime": 7` or `5`.
+var seedFieldRegexp = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_-]*)?["']?\s*[=:]?\s*(-?[0-9]+)`)
+
+// Parses a seed copied from logs, chat or config. Accepted inputs include
+// three numbers in the order prime, modInverse, random separated by commas,
+// semicolons, whitespace or newlines and optionally surrounded by
+// brackets, as well as keyed fields such as "prime=..., modInverse=...,
+// random=..." and every output of FormatSeed. If keys are used the
+// modInverse may be omitted and is calculated. The seed is validated.
+func ParseSeedString(s string) (Optimus, error) {
+	var keyed, unkeyed []uint64
+	fields := make(map[string]uint64)
+
+	for _, match := range seedFieldRegexp.FindAllStringSubmatch(s, -1) {
+		n, err := strconv.ParseUint(match[2], 10, 64)
+		if err != nil {
+			return Optimus{}, jsonerror.New(31, "Invalid seed string", fmt.Sprintf("%q is not an unsigned 64-bit integer", match[2]))
+		}
+
+		key := seedFieldKey(match[1])
+		if key == "" {
+			unkeyed = append(unkeyed, n)
+			continue
+		}
+		if _, ok := fields[key]; ok {
+			return Optimus{}, jsonerror.New(31, "Invalid seed string", fmt.Sprintf("%s appears more than once", key))
+		}
+		fields[key] = n
+		keyed = append(keyed, n)
+	}
+
+	if len(keyed) > 0 && len(unkeyed) > 0 {
+		return Optimus{}, jsonerror.New(31, "Invalid seed string", "Mixes keyed and unkeyed numbers")
+	}
+
+	if len(unkeyed) > 0 {
+		if len(unkeyed) != 3 {
+			return Optimus{}, jsonerror.New(31, "Invalid seed string", fmt.Sprintf("Expected 3 numbers. Found %d", len(unkeyed)))
+		}
+		fields = map[string]uint64{"prime": unkeyed[0], "modInverse": unkeyed[1], "random": unkeyed[2]}
+	}
+
+	if strings.Contains(s, "MODE_ADDITIVE") {
+		fields["mode"] = uint64(MODE_ADDITIVE)
+	}
+	return seedFromFields(fields)
+}
+
+// Returns the validated seed for the fields named by seedFieldKey. The
+// modInverse is calculated if it is missing.
+func seedFromFields(fields map[string]uint64) (Optimus, error) {
+	prime, ok := fields["prime"]
+	if !ok {
+		return Optimus{}, jsonerror.New(31, "Invalid seed string", "No prime found")
+	}
+	random, ok := fields["random"]
+	if !ok {
+		return Optimus{}, jsonerror.New(31, "Invalid seed string", "No random found")
+	}
+
+	if err := validatePrime(prime); err != nil {
+		return Optimus{}, err
+	}
+
+	modInverse, ok := fields["modInverse"]
+	if !ok {
+		modInverse = ModInverse(prime)
+	} else if prime*modInverse != 1 {
+		return Optimus{}, jsonerror.New(15, "Invalid modInverse", fmt.Sprintf("%d is not the inverse of %d modulo 2^64", modInverse, prime))
+	}
+
+	mode := Mode(fields["mode"])
+	if fields["mode"] > uint64(MODE_ADDITIVE) {
+		return Optimus{}, jsonerror.New(31, "Invalid seed string", fmt.Sprintf("Unknown mode %d", fields["mode"]))
+	}
+
+	if isIdentity(prime, modInverse, random) {
+		return Optimus{}, ErrIdentityTransform
+	}
+
+	return Optimus{prime: prime, modInverse: modInverse, random: random, mode: mode}, nil
+}
+
+// Returns the field named by key, ignoring case, separators and prefixes
+// such as OPTIMUS_. Returns "" if key does not name a field.
+func seedFieldKey(key string) string {
+	key = strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	switch {
+	case strings.HasSuffix(key, "inverse"):
+		return "modInverse"
+	case strings.HasSuffix(key, "prime"):
+		return "prime"
+	case strings.HasSuffix(key, "random"):
+		return "random"
+	case strings.HasSuffix(key, "mode"):
+		return "mode"
+	}
+	return ""
+}
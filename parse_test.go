@@ -0,0 +1,69 @@
+package optimus
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Tests that messy but recoverable seed strings are parsed.
+func TestParseSeedString(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	p, i, r := o.Prime(), o.ModInverse(), o.Random()
+
+	inputs := []string{
+		fmt.Sprintf("%d, %d, %d", p, i, r),
+		fmt.Sprintf("  [%d,%d,%d]  ", p, i, r),
+		fmt.Sprintf("(%d;\t%d;  %d)", p, i, r),
+		fmt.Sprintf("%d\n%d\n%d\n", p, i, r),
+		fmt.Sprintf("prime=%d, modInverse=%d, random=%d", p, i, r),
+		fmt.Sprintf("{ Prime: %d,\n  Random: %d }", p, r),
+		fmt.Sprintf("random = %d\nmod-inverse = %d\nprime = %d", r, i, p),
+	}
+	for _, format := range []string{"json", "env", "yaml", "go"} {
+		s, _ := FormatSeed(o, format)
+		inputs = append(inputs, s)
+	}
+
+	for _, input := range inputs {
+		parsed, err := ParseSeedString(input)
+		if err != nil || parsed != o {
+			t.Errorf("%q: Expected %v. Got %v (%v) - FAILED", input, o, parsed, err)
+		}
+	}
+
+	additive := o.WithMode(MODE_ADDITIVE)
+	for _, format := range []string{"json", "env", "yaml", "go"} {
+		s, _ := FormatSeed(additive, format)
+		if parsed, err := ParseSeedString(s); err != nil || parsed != additive {
+			t.Errorf("%q: Expected %v. Got %v (%v) - FAILED", s, additive, parsed, err)
+		}
+	}
+}
+
+// Tests that invalid seed strings are rejected.
+func TestParseSeedStringInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	p, i, r := o.Prime(), o.ModInverse(), o.Random()
+
+	inputs := []string{
+		"",
+		"hello world",
+		fmt.Sprintf("%d, %d", p, i),
+		fmt.Sprintf("%d, %d, %d, 4", p, i, r),
+		fmt.Sprintf("4, %d, %d", i, r),
+		fmt.Sprintf("%d, %d, %d", p, i+1, r),
+		fmt.Sprintf("-%d, %d, %d", p, i, r),
+		fmt.Sprintf("prime=%d, %d, random=%d", p, i, r),
+		fmt.Sprintf("prime=%d, prime=%d, random=%d", p, p, r),
+		fmt.Sprintf("prime=%d, modInverse=%d", p, i),
+		fmt.Sprintf("prime=%d, random=%d, mode=7", p, r),
+		fmt.Sprintf("%d, %d, %s", p, i, strings.Repeat("9", 25)),
+	}
+
+	for _, input := range inputs {
+		if parsed, err := ParseSeedString(input); err == nil {
+			t.Errorf("Expected %q to be rejected. Got %v", input, parsed)
+		}
+	}
+}
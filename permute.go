@@ -0,0 +1,35 @@
+package optimus
+
+import (
+	"sort"
+)
+
+// Returns a copy of ids reordered by their encoded values. The order only
+// depends on the seed and the set of ids, so a list such as 1..N is always
+// shuffled the same way. This is intended for presentation purposes and
+// provides no security.
+func (this Optimus) Permute(ids []uint64) []uint64 {
+	index := this.PermuteIndex(ids)
+
+	shuffled := make([]uint64, len(ids))
+	for i, p := range index {
+		shuffled[i] = ids[p]
+	}
+	return shuffled
+}
+
+// Returns the inverse mapping of Permute: the position in ids of each
+// element of the shuffled list, such that ids[index[i]] == Permute(ids)[i].
+func (this Optimus) PermuteIndex(ids []uint64) []int {
+	index := make([]int, len(ids))
+	encoded := make([]uint64, len(ids))
+	for i, id := range ids {
+		index[i] = i
+		encoded[i] = this.Encode(id)
+	}
+
+	sort.SliceStable(index, func(a, b int) bool {
+		return encoded[index[a]] < encoded[index[b]]
+	})
+	return index
+}
@@ -0,0 +1,67 @@
+package optimus
+
+import (
+	"sort"
+	"testing"
+)
+
+// Tests that Permute is a deterministic bijection over the ids and that
+// PermuteIndex reverses it.
+func TestPermute(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	var ids []uint64
+	for i := uint64(1); i <= 100; i++ {
+		ids = append(ids, i)
+	}
+
+	shuffled := o.Permute(ids)
+	index := o.PermuteIndex(ids)
+
+	//Bijection: same elements, different order
+	sorted := append([]uint64(nil), shuffled...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+	for i := range ids {
+		if sorted[i] != ids[i] {
+			t.Fatalf("Permute is not a bijection: %v", shuffled)
+		}
+	}
+
+	same := true
+	for i := range ids {
+		if shuffled[i] != ids[i] {
+			same = false
+		}
+	}
+	if same {
+		t.Errorf("Expected the ids to be shuffled")
+	}
+
+	//Reversible
+	restored := make([]uint64, len(ids))
+	for i, p := range index {
+		restored[p] = shuffled[i]
+	}
+	for i := range ids {
+		if restored[i] != ids[i] {
+			t.Errorf("%d: restored %d - FAILED", ids[i], restored[i])
+		}
+	}
+
+	//Sorted by encoded value and independent of input order
+	for i := 1; i < len(shuffled); i++ {
+		if o.Encode(shuffled[i-1]) > o.Encode(shuffled[i]) {
+			t.Errorf("Permutation is not ordered by encoded value at %d", i)
+		}
+	}
+
+	reversed := make([]uint64, len(ids))
+	for i := range ids {
+		reversed[len(ids)-1-i] = ids[i]
+	}
+	for i, id := range o.Permute(reversed) {
+		if id != shuffled[i] {
+			t.Errorf("Expected permutation to be independent of input order at %d", i)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"runtime"
+	"sync"
+)
+
+const (
+	TRIAL_DIVISION_BOUND = 1000 // Primes below this are used for trial division by isPrimeStrong
+	VALIDATION_ATTEMPTS  = 5    // Number of seeds GenerateValidatedSeed tries before giving up
+)
+
+// Miller-Rabin witnesses which are sufficient to deterministically test
+// every n < 2^64.
+var strongWitnesses = []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+
+// Composites which fool weak primality tests: Carmichael numbers, which
+// pass the Fermat test for every coprime base, and the smallest strong
+// pseudoprimes to each prefix of the prime bases. NewStrict rejects them
+// outright. 3825123056546413051 passes Miller-Rabin for every base up to 31.
+var knownPseudoprimes = map[uint64]bool{
+	561: true, 1105: true, 1729: true, 2465: true, 2821: true, 6601: true, 8911: true,
+	2047: true, 3277: true, 4033: true, 4681: true, 8321: true,
+	1373653:             true,
+	25326001:            true,
+	3215031751:          true,
+	4759123141:          true,
+	2152302898747:       true,
+	3474749660383:       true,
+	341550071728321:     true,
+	3825123056546413051: true,
+}
+
+// Returns an Optimus struct like NewCalculated but validates the prime
+// strictly: it is rejected if it is a known pseudoprime and must also pass
+// trial division and deterministic Miller-Rabin (see GenerateValidatedSeed).
+// Returns an error instead of panicking.
+func NewStrict(prime uint64, random uint64) (Optimus, error) {
+	if isIdentity(prime, prime, random) {
+		return Optimus{}, ErrIdentityTransform
+	}
+
+	if err := validatePrimeStrict(prime); err != nil {
+		return Optimus{}, err
+	}
+
+	warnInsecurePrime(prime)
+
+	return Optimus{prime: prime, modInverse: ModInverse(prime), random: random}, nil
+}
+
+// Returns an error if n can not be used as the prime of an Optimus or
+// fails the stricter checks of NewStrict.
+func validatePrimeStrict(n uint64) error {
+	if knownPseudoprimes[n] {
+		return jsonerror.New(2, "Number is not prime", fmt.Sprintf("n=%d is a known pseudoprime", n))
+	}
+	if err := validatePrime(n); err != nil {
+		return err
+	}
+	if !isPrimeStrong(n) {
+		return notPrimeError(n)
+	}
+	return nil
+}
+
+// Reports whether n is prime using a test which is independent of
+// math/big: trial division by every prime below TRIAL_DIVISION_BOUND
+// followed by Miller-Rabin with a deterministic set of witnesses.
+func isPrimeStrong(n uint64) bool {
+	if n < 2 {
+		return false
+	}
+
+	for d := uint64(2); d < TRIAL_DIVISION_BOUND; d++ {
+		if d*d > n {
+			return true
+		}
+		if n%d == 0 {
+			return false
+		}
+	}
+
+	//n - 1 = d * 2^s
+	d, s := n-1, 0
+	for d&1 == 0 {
+		d >>= 1
+		s++
+	}
+
+	for _, a := range strongWitnesses {
+		x := powMod(a%n, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+
+		composite := true
+		for r := 1; r < s; r++ {
+			x = mulMod(x, x, n)
+			if x == n-1 {
+				composite = false
+				break
+			}
+		}
+		if composite {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns (b ^ e) mod m.
+func powMod(b uint64, e uint64, m uint64) uint64 {
+	result := uint64(1) % m
+	for e > 0 {
+		if e&1 == 1 {
+			result = mulMod(result, b, m)
+		}
+		b = mulMod(b, b, m)
+		e >>= 1
+	}
+	return result
+}
+
+// Generates seeds from src until one has a prime which also passes
+// isPrimeStrong, giving up after VALIDATION_ATTEMPTS tries.
+func generateValidatedSeed(src PrimeSource) (*Optimus, error) {
+	var lastErr error
+	for i := 0; i < VALIDATION_ATTEMPTS; i++ {
+		o, err := GenerateSeedFrom(src)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if isPrimeStrong(o.Prime()) {
+			return o, nil
+		}
+		lastErr = notPrimeError(o.Prime())
+	}
+	return nil, jsonerror.New(1, "Could not generate seed", lastErr.Error())
+}
+
+// Validates each of ns as the prime of an Optimus, spreading the work over
+// GOMAXPROCS goroutines. The result is index-aligned with ns and is nil
+// where the number is valid.
+func ValidatePrimes(ns []uint64) []error {
+	errs := make([]error, len(ns))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(ns) {
+		workers = len(ns)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = validatePrime(ns[i])
+			}
+		}()
+	}
+
+	for i := range ns {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,188 @@
+package optimus
+
+import (
+	"math/big"
+	"testing"
+)
+
+// Tests the independent primality check against known primes, composites
+// and strong pseudoprimes.
+func TestIsPrimeStrong(t *testing.T) {
+	primes := []uint64{2, 3, 997, 1009, 7919, 1580030173, 4294967291, 9223372036854775783, 18446744073709551557}
+	composites := []uint64{0, 1, 4, 561, 1009 * 1013, 3215031751, 3825123056546413051, 18446744073709551615}
+
+	for _, n := range primes {
+		if !isPrimeStrong(n) {
+			t.Errorf("Expected %d to be prime", n)
+		}
+	}
+
+	for _, n := range composites {
+		if isPrimeStrong(n) {
+			t.Errorf("Expected %d to be composite", n)
+		}
+	}
+}
+
+// Tests that NewStrict rejects known pseudoprimes even when the
+// Miller-Rabin witnesses used would let them through.
+func TestNewStrict(t *testing.T) {
+	if o, err := NewStrict(1580030173, 1163945558); err != nil || o != NewCalculated(1580030173, 1163945558) {
+		t.Errorf("Expected a valid seed. Got %v (%v)", o, err)
+	}
+
+	for n := range knownPseudoprimes {
+		if new(big.Int).SetUint64(n).ProbablyPrime(0) {
+			t.Errorf("%d is prime", n)
+		}
+		if _, err := NewStrict(n, 1163945558); err == nil {
+			t.Errorf("Expected %d to be rejected", n)
+		}
+	}
+
+	//With only the bases up to 31, Miller-Rabin passes 3825123056546413051.
+	//A weak PrimalityTest passes it too, so only the blacklist rejects it.
+	defer func(witnesses []uint64) { strongWitnesses = witnesses }(strongWitnesses)
+	strongWitnesses = strongWitnesses[:11]
+	defer func(test func(uint64) bool) { PrimalityTest = test }(PrimalityTest)
+	PrimalityTest = func(n uint64) bool {
+		return n == 3825123056546413051 || new(big.Int).SetUint64(n).ProbablyPrime(MILLER_RABIN)
+	}
+	if !isPrimeStrong(3825123056546413051) {
+		t.Errorf("Expected 3825123056546413051 to pass the bases up to 31")
+	}
+	if err := validatePrime(3825123056546413051); err != nil {
+		t.Errorf("Expected 3825123056546413051 to pass the weak test. Got %v", err)
+	}
+	if _, err := NewStrict(3825123056546413051, 1163945558); err == nil {
+		t.Errorf("Expected 3825123056546413051 to be rejected")
+	}
+
+	//Without the blacklist entry it would have been accepted
+	delete(knownPseudoprimes, 3825123056546413051)
+	_, err := NewStrict(3825123056546413051, 1163945558)
+	knownPseudoprimes[3825123056546413051] = true
+	if err != nil {
+		t.Errorf("Expected only the blacklist to reject 3825123056546413051. Got %v", err)
+	}
+
+	for _, prime := range []uint64{2, 1580030175} {
+		if _, err := NewStrict(prime, 1163945558); err == nil {
+			t.Errorf("Expected %d to be rejected", prime)
+		}
+	}
+	if _, err := NewStrict(1, 0); err != ErrIdentityTransform {
+		t.Errorf("Expected ErrIdentityTransform. Got %v", err)
+	}
+}
+
+// Tests that New, NewCalculated, ModInverse and the seed validators consult
+// PrimalityTest.
+func TestPrimalityTest(t *testing.T) {
+	defer func(test func(uint64) bool) { PrimalityTest = test }(PrimalityTest)
+
+	var tested []uint64
+	PrimalityTest = func(n uint64) bool {
+		tested = append(tested, n)
+		return n != 1580030173
+	}
+
+	panics := map[string]func(){
+		"New":           func() { New(1580030173, 1, 1163945558) },
+		"NewCalculated": func() { NewCalculated(1580030173, 1163945558) },
+		"ModInverse":    func() { ModInverse(1580030173) },
+	}
+	for name, f := range panics {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected %s to panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+
+	if _, err := DeriveSeed(1580030173); err == nil {
+		t.Errorf("Expected DeriveSeed to reject the prime")
+	}
+	if _, err := NewWithMinAccuracy(1580030173, 1163945558, 0.5); err == nil {
+		t.Errorf("Expected NewWithMinAccuracy to reject the prime")
+	}
+
+	//The backend is trusted, even for a composite
+	tested = nil
+	if inverse := ModInverse(1580030175); inverse*1580030175 != 1 || len(tested) != 1 || tested[0] != 1580030175 {
+		t.Errorf("Expected ModInverse to consult the backend. Got %d after testing %v", inverse, tested)
+	}
+	if o := New(2123809381, ModInverse(2123809381), 1198752319); o.Decode(o.Encode(15)) != 15 {
+		t.Errorf("15: -> %d - FAILED", o.Decode(o.Encode(15)))
+	}
+}
+
+// Tests that the validated generator returns a prime which passes the
+// stronger check and retries when the candidate is composite.
+func TestGenerateValidatedSeed(t *testing.T) {
+	o, err := generateValidatedSeed(StubPrimeSource{1580030173})
+	if err != nil || !isPrimeStrong(o.Prime()) {
+		t.Errorf("Expected a validated seed. Got %v (%v)", o, err)
+	}
+
+	o, err = generateValidatedSeed(&flakyPrimeSource{[]uint64{1009 * 1013, 1580030173}})
+	if err != nil || o.Prime() != 1580030173 {
+		t.Errorf("Expected retry to return 1580030173. Got %v (%v)", o, err)
+	}
+
+	if _, err := generateValidatedSeed(StubPrimeSource{1009 * 1013}); err == nil {
+		t.Errorf("Expected composite candidates to fail")
+	}
+}
+
+// Returns one candidate at a time.
+type flakyPrimeSource struct {
+	candidates []uint64
+}
+
+func (this *flakyPrimeSource) Primes() ([]uint64, error) {
+	c := this.candidates[0]
+	this.candidates = this.candidates[1:]
+	return []uint64{c}, nil
+}
+
+// Tests that ValidatePrimes reports an error for each composite and nil for
+// each prime at the matching index.
+func TestValidatePrimes(t *testing.T) {
+	ns := []uint64{1580030173, 4, 2, 4294967291, 1580030175, 9223372036854775783, 0, 7919}
+	valid := []bool{true, false, false, true, false, true, false, true}
+
+	errs := ValidatePrimes(ns)
+	if len(errs) != len(ns) {
+		t.Fatalf("Expected %d results. Got %d", len(ns), len(errs))
+	}
+
+	for i, n := range ns {
+		if (errs[i] == nil) != valid[i] {
+			t.Errorf("%d: Expected valid=%t. Got %v - FAILED", n, valid[i], errs[i])
+		}
+	}
+
+	if errs[2] != ErrEvenPrime {
+		t.Errorf("Expected ErrEvenPrime for 2. Got %v", errs[2])
+	}
+
+	if errs := ValidatePrimes(nil); len(errs) != 0 {
+		t.Errorf("Expected no results. Got %v", errs)
+	}
+}
+
+func BenchmarkValidatePrimes(b *testing.B) {
+	ns := make([]uint64, 10000)
+	for i := range ns {
+		ns[i] = 1<<62 + uint64(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ValidatePrimes(ns)
+	}
+}
@@ -0,0 +1,48 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// RangedObfuscator confines the outputs of a Modular to the interval
+// [lo, hi], eg. to tell obfuscated ids apart from another id type by
+// magnitude. Each output is the Modular's encoding offset by lo.
+// NB: Optimus works over the whole 2^64 domain so it can not be confined to
+// a smaller interval. Use a Modular whose modulus is the number of ids you
+// need.
+type RangedObfuscator struct {
+	core Modular
+	lo   uint64
+}
+
+// Returns a RangedObfuscator which encodes using core and offsets the
+// result into [lo, hi]. Returns an error if lo is greater than hi or the
+// interval holds fewer values than the modulus of core.
+func NewRangedObfuscator(core Modular, lo uint64, hi uint64) (RangedObfuscator, error) {
+	if lo > hi {
+		return RangedObfuscator{}, jsonerror.New(40, "Invalid range", fmt.Sprintf("lo=%d hi=%d. lo must not exceed hi", lo, hi))
+	}
+	if hi-lo < core.modulus-1 {
+		return RangedObfuscator{}, jsonerror.New(40, "Invalid range", fmt.Sprintf("[%d, %d] holds %d values. At least the modulus %d are needed", lo, hi, hi-lo+1, core.modulus))
+	}
+	return RangedObfuscator{core, lo}, nil
+}
+
+// Encodes n into [lo, lo + modulus - 1]. Returns an error if n is not less
+// than the modulus.
+func (this RangedObfuscator) Encode(n uint64) (uint64, error) {
+	if n >= this.core.modulus {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("n=%d. Must be less than %d", n, this.core.modulus))
+	}
+	return this.lo + this.core.Encode(n), nil
+}
+
+// Decodes a number produced by Encode. Returns an error if n is outside
+// the range of possible encodings.
+func (this RangedObfuscator) Decode(n uint64) (uint64, error) {
+	if n < this.lo || n-this.lo >= this.core.modulus {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("%d is not an encoding", n))
+	}
+	return this.core.Decode(n - this.lo), nil
+}
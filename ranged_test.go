@@ -0,0 +1,68 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that every output falls in [lo, hi] and round-trips.
+func TestRangedObfuscator(t *testing.T) {
+	core, err := NewModular(7919, 12345, 65521)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ranges := []struct{ lo, hi uint64 }{
+		{1 << 40, 1<<40 + 65520}, //exactly the modulus
+		{1 << 40, 1<<41 - 1},
+		{0, 65520},
+		{MAX_INT - 65520, MAX_INT},
+	}
+
+	for _, rng := range ranges {
+		r, err := NewRangedObfuscator(core, rng.lo, rng.hi)
+		if err != nil {
+			t.Errorf("[%d, %d] - FAILED: %v", rng.lo, rng.hi, err)
+			continue
+		}
+
+		seen := make(map[uint64]bool)
+		for n := uint64(0); n < 65521; n++ {
+			encoded, err := r.Encode(n)
+			if err != nil || encoded < rng.lo || encoded > rng.hi {
+				t.Fatalf("[%d, %d]: %d encoded to %d (%v)", rng.lo, rng.hi, n, encoded, err)
+			}
+			if seen[encoded] {
+				t.Fatalf("[%d, %d]: %d collides on %d", rng.lo, rng.hi, n, encoded)
+			}
+			seen[encoded] = true
+
+			if decoded, err := r.Decode(encoded); err != nil || decoded != n {
+				t.Fatalf("[%d, %d]: %d -> %d (%v) - FAILED", rng.lo, rng.hi, encoded, decoded, err)
+			}
+		}
+
+		if _, err := r.Encode(65521); err == nil {
+			t.Errorf("Expected an id outside the modulus to be rejected")
+		}
+		for _, bad := range []uint64{rng.lo - 1, rng.lo + 65521} {
+			if _, err := r.Decode(bad); err == nil {
+				t.Errorf("[%d, %d]: Expected %d to be rejected", rng.lo, rng.hi, bad)
+			}
+		}
+	}
+}
+
+// Tests that intervals which are inverted or too small are rejected.
+func TestRangedObfuscatorInvalid(t *testing.T) {
+	core, _ := NewModular(7919, 12345, 65521)
+
+	for _, rng := range []struct{ lo, hi uint64 }{
+		{1000, 999},
+		{1000, 1000 + 65519},
+		{0, 0},
+	} {
+		if _, err := NewRangedObfuscator(core, rng.lo, rng.hi); err == nil {
+			t.Errorf("Expected [%d, %d] to be rejected", rng.lo, rng.hi)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"sync"
+)
+
+// Registry holds named seeds, eg. one each for users, orders and invoices,
+// so that seed management is centralized. It is safe for concurrent use.
+// The zero value is an empty Registry.
+type Registry struct {
+	mu    sync.RWMutex
+	seeds map[string]Optimus
+}
+
+// Returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{seeds: make(map[string]Optimus)}
+}
+
+// Registers o under name, replacing any Optimus already registered under
+// that name.
+func (this *Registry) Register(name string, o Optimus) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	if this.seeds == nil {
+		this.seeds = make(map[string]Optimus)
+	}
+	this.seeds[name] = o
+}
+
+// Returns the Optimus registered under name and whether there is one.
+func (this *Registry) Get(name string) (Optimus, bool) {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+
+	o, ok := this.seeds[name]
+	return o, ok
+}
+
+// Returns the Optimus registered under name. Panics if there is none, which
+// is a programming error such as a typo in the name.
+func (this *Registry) MustGet(name string) Optimus {
+	o, ok := this.Get(name)
+	if !ok {
+		panic(jsonerror.New(39, "Unknown seed", fmt.Sprintf("No Optimus registered under %q", name)))
+	}
+	return o
+}
@@ -0,0 +1,80 @@
+package optimus
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Tests registration, lookup and overwriting.
+func TestRegistry(t *testing.T) {
+	users := NewCalculated(1580030173, 1163945558)
+	orders := NewCalculated(2123809381, 1163945558)
+
+	r := NewRegistry()
+	r.Register("users", users)
+	r.Register("orders", users)
+	r.Register("orders", orders)
+
+	if o, ok := r.Get("users"); !ok || o != users {
+		t.Errorf("Expected the users seed. Got %v (%t)", o, ok)
+	}
+	if o, ok := r.Get("orders"); !ok || o != orders {
+		t.Errorf("Expected the orders seed to be overwritten. Got %v (%t)", o, ok)
+	}
+	if _, ok := r.Get("invoices"); ok {
+		t.Errorf("Expected no invoices seed")
+	}
+	if r.MustGet("users") != users {
+		t.Errorf("Expected MustGet to return the users seed")
+	}
+
+	//The zero value is usable
+	var zero Registry
+	if _, ok := zero.Get("users"); ok {
+		t.Errorf("Expected the zero value to be empty")
+	}
+	zero.Register("users", users)
+	if o, ok := zero.Get("users"); !ok || o != users {
+		t.Errorf("Expected the users seed. Got %v (%t)", o, ok)
+	}
+}
+
+// Tests that MustGet panics for unknown names.
+func TestRegistryMustGet(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected MustGet to panic")
+		}
+	}()
+	NewRegistry().MustGet("invoices")
+}
+
+// Tests that the registry can be used from many goroutines at once.
+// Run with -race.
+func TestRegistryConcurrent(t *testing.T) {
+	r := NewRegistry()
+	o := NewCalculated(1580030173, 1163945558)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				name := fmt.Sprintf("seed%d", (g+i)%16)
+				r.Register(name, o)
+				if got, ok := r.Get(name); !ok || got != o {
+					t.Errorf("%s: Unexpected %v (%t)", name, got, ok)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for i := 0; i < 16; i++ {
+		if _, ok := r.Get(fmt.Sprintf("seed%d", i)); !ok {
+			t.Errorf("Expected seed%d to be registered", i)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !optimus_debug
+// +build !optimus_debug
+
+package optimus
+
+// Disables domain and seed assertions. Build with the optimus_debug tag to
+// enable them. Since debug is a constant, checks guarded by it are removed
+// by the compiler.
+const debug = false
+
+func assertInDomain(what string, n uint64, max uint64) {}
+
+func assertSeed(prime uint64, modInverse uint64) {}
@@ -0,0 +1,67 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// ReservedEncoder wraps an Optimus so that Encode never produces one of a set
+// of reserved values (eg. 0 meaning "no id").
+//
+// Whenever Encode would produce a reserved value, the result is encoded
+// again until a non-reserved value is reached (cycle walking). Decode walks
+// back the same way. This keeps the mapping injective, but reserved values
+// cannot be encoded or decoded themselves, so the usable domain shrinks by
+// the number of reserved values.
+type ReservedEncoder struct {
+	optimus  Optimus
+	reserved map[uint64]struct{}
+}
+
+// Returns a ReservedEncoder which encodes using o and never outputs any of
+// the reserved values.
+func NewReservedEncoder(o Optimus, reserved ...uint64) ReservedEncoder {
+	m := make(map[uint64]struct{}, len(reserved))
+	for _, r := range reserved {
+		m[r] = struct{}{}
+	}
+	return ReservedEncoder{o, m}
+}
+
+// Encodes n, skipping over reserved values. Returns an error if n is itself
+// a reserved value.
+func (this ReservedEncoder) Encode(n uint64) (uint64, error) {
+	if this.IsReserved(n) {
+		return 0, reservedError(n)
+	}
+
+	c := this.optimus.Encode(n)
+	for this.IsReserved(c) {
+		c = this.optimus.Encode(c)
+	}
+	return c, nil
+}
+
+// Decodes a number produced by Encode. Returns an error if n is a reserved
+// value.
+func (this ReservedEncoder) Decode(n uint64) (uint64, error) {
+	if this.IsReserved(n) {
+		return 0, reservedError(n)
+	}
+
+	d := this.optimus.Decode(n)
+	for this.IsReserved(d) {
+		d = this.optimus.Decode(d)
+	}
+	return d, nil
+}
+
+// Reports whether n is a reserved value.
+func (this ReservedEncoder) IsReserved(n uint64) bool {
+	_, ok := this.reserved[n]
+	return ok
+}
+
+func reservedError(n uint64) error {
+	return jsonerror.New(7, "Reserved value", fmt.Sprintf("%d is reserved and can not be encoded or decoded", n))
+}
@@ -0,0 +1,52 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that reserved values are never produced by Encode and that the
+// values which would have produced them still round-trip.
+func TestReservedEncoder(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	//These inputs encode to the reserved values with the plain Optimus
+	zeroInput := o.Decode(0)
+	sentinelInput := o.Decode(MAX_INT)
+
+	r := NewReservedEncoder(o, 0, MAX_INT, o.Encode(zeroInput+1))
+
+	for _, value := range []uint64{zeroInput, sentinelInput, zeroInput + 1, 1, 15, MAX_INT - 1} {
+		encoded, err := r.Encode(value)
+		if err != nil {
+			t.Errorf("%d - FAILED: %v", value, err)
+			continue
+		}
+
+		if r.IsReserved(encoded) {
+			t.Errorf("%d encoded to reserved value %d", value, encoded)
+		}
+
+		decoded, err := r.Decode(encoded)
+		if err != nil || decoded != value {
+			t.Errorf("%d: %d -> %d (%v) - FAILED", value, encoded, decoded, err)
+		}
+	}
+
+	//Values which don't hit a reserved value are unchanged
+	if encoded, _ := r.Encode(15); encoded != o.Encode(15) {
+		t.Errorf("Expected %d. Got %d", o.Encode(15), encoded)
+	}
+}
+
+// Tests that reserved values are rejected as inputs.
+func TestReservedEncoderRejectsReserved(t *testing.T) {
+	r := NewReservedEncoder(NewCalculated(1580030173, 1163945558), 0)
+
+	if _, err := r.Encode(0); err == nil {
+		t.Errorf("Expected Encode(0) to fail")
+	}
+
+	if _, err := r.Decode(0); err == nil {
+		t.Errorf("Expected Decode(0) to fail")
+	}
+}
@@ -0,0 +1,20 @@
+package optimus
+
+// EncodeResult holds the encoded forms of an id. See EncodeFull.
+type EncodeResult struct {
+	Int     uint64 // Encode(n)
+	String  string // Base62 representation of Int. See EncodeToString
+	Bits    uint8  // Bit width of the domain
+	Version byte   // Version of the seed. See WithVersion
+}
+
+// Encodes n and returns every form of the result in one call.
+func (this Optimus) EncodeFull(n uint64) EncodeResult {
+	encoded := this.Encode(n)
+	return EncodeResult{
+		Int:     encoded,
+		String:  base62Encode(encoded),
+		Bits:    DEFAULT_BITS,
+		Version: this.version,
+	}
+}
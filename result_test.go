@@ -0,0 +1,36 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that every field of EncodeFull is consistent with the individual
+// methods.
+func TestEncodeFull(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558).WithVersion(3)
+
+	for _, value := range []uint64{0, 15, MAX_INT} {
+		r := o.EncodeFull(value)
+
+		if r.Int != o.Encode(value) {
+			t.Errorf("%d: Expected Int %d. Got %d", value, o.Encode(value), r.Int)
+		}
+		if r.String != o.EncodeToString(value) {
+			t.Errorf("%d: Expected String %s. Got %s", value, o.EncodeToString(value), r.String)
+		}
+		if r.Bits != 64 {
+			t.Errorf("%d: Expected 64 bits. Got %d", value, r.Bits)
+		}
+		if r.Version != 3 {
+			t.Errorf("%d: Expected version 3. Got %d", value, r.Version)
+		}
+
+		if n, err := o.DecodeFromString(r.String); err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, r.String, n, err)
+		}
+	}
+
+	if o.WithVersion(4).Encode(15) != o.Encode(15) {
+		t.Errorf("Expected the version not to affect encoding")
+	}
+}
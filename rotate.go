@@ -0,0 +1,44 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Decodes encoded using oldSeed and encodes the result using newSeed. Use it
+// to migrate stored obfuscated ids when rotating seeds. Returns an error if
+// encoded is not a canonical encoding under oldSeed.
+func Reencode(oldSeed Optimus, newSeed Optimus, encoded uint64) (uint64, error) {
+	n, ok := oldSeed.DecodeOK(encoded)
+	if !ok {
+		return 0, jsonerror.New(9, "Could not reencode", fmt.Sprintf("%d is not a canonical encoding under the old seed", encoded))
+	}
+	return newSeed.Encode(n), nil
+}
+
+// Reencodes each value in encoded. See Reencode. Returns the first error
+// encountered along with its index.
+func ReencodeSlice(oldSeed Optimus, newSeed Optimus, encoded []uint64) ([]uint64, error) {
+	result := make([]uint64, len(encoded))
+	for i, value := range encoded {
+		n, err := Reencode(oldSeed, newSeed, value)
+		if err != nil {
+			return nil, jsonerror.New(9, "Could not reencode", fmt.Sprintf("index %d: %s", i, err.Error()))
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+// Returns a copy of the Optimus using prime, with the modInverse
+// recalculated and the random number, mode and version kept. Use it to
+// rotate only the prime. Returns an error if prime is not valid.
+func (this Optimus) WithPrime(prime uint64) (Optimus, error) {
+	if err := validatePrime(prime); err != nil {
+		return Optimus{}, err
+	}
+
+	this.prime = prime
+	this.modInverse = ModInverse(prime)
+	return this, nil
+}
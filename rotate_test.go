@@ -0,0 +1,82 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that values encoded with the old seed decode to the original ids
+// with the new seed after being reencoded.
+func TestReencode(t *testing.T) {
+	oldSeed := NewCalculated(1580030173, 1163945558)
+	newSeed := NewCalculated(2123809381, 1198752319)
+
+	ids := []uint64{0, 1, 15, 1 << 40, MAX_INT}
+
+	var stored []uint64
+	for _, id := range ids {
+		encoded := oldSeed.Encode(id)
+		stored = append(stored, encoded)
+
+		reencoded, err := Reencode(oldSeed, newSeed, encoded)
+		if err != nil || newSeed.Decode(reencoded) != id {
+			t.Errorf("%d: %d -> %d (%v) - FAILED", id, encoded, reencoded, err)
+		}
+	}
+
+	reencoded, err := ReencodeSlice(oldSeed, newSeed, stored)
+	if err != nil {
+		t.Fatalf("ReencodeSlice - FAILED: %v", err)
+	}
+	for i, id := range ids {
+		if newSeed.Decode(reencoded[i]) != id {
+			t.Errorf("%d: %d -> %d - FAILED", id, stored[i], reencoded[i])
+		}
+	}
+}
+
+// Tests that an inconsistent old seed is reported.
+func TestReencodeInconsistentSeed(t *testing.T) {
+	if debug {
+		t.Skip("Debug builds panic on inconsistent seeds")
+	}
+	oldSeed := New(1580030173, 59260789, 1163945558) //modInverse is for 2^31
+	newSeed := NewCalculated(2123809381, 1198752319)
+
+	if _, err := ReencodeSlice(oldSeed, newSeed, []uint64{oldSeed.Encode(15)}); err == nil {
+		t.Errorf("Expected inconsistent seed to be reported")
+	}
+}
+
+// Tests that WithPrime keeps the random number and recalculates the
+// modInverse.
+func TestWithPrime(t *testing.T) {
+	for _, o := range []Optimus{
+		NewCalculated(1580030173, 1163945558),
+		NewCalculated(1580030173, 1163945558).WithMode(MODE_ADDITIVE).WithVersion(2),
+	} {
+		rotated, err := o.WithPrime(2123809381)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if rotated.Random() != o.Random() || rotated.Mode() != o.Mode() || rotated.Version() != o.Version() {
+			t.Errorf("Expected random, mode and version to be kept. Got %v", rotated)
+		}
+		if rotated.Prime() != 2123809381 || rotated.Prime()*rotated.ModInverse() != 1 {
+			t.Errorf("Invalid prime %d and modInverse %d", rotated.Prime(), rotated.ModInverse())
+		}
+
+		for _, value := range []uint64{0, 15, MAX_INT} {
+			if got := rotated.Decode(rotated.Encode(value)); got != value {
+				t.Errorf("%d: -> %d - FAILED", value, got)
+			}
+		}
+	}
+
+	o := NewCalculated(1580030173, 1163945558)
+	for _, prime := range []uint64{0, 1, 2, 1580030175} {
+		if _, err := o.WithPrime(prime); err == nil {
+			t.Errorf("Expected %d to be rejected", prime)
+		}
+	}
+}
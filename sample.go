@@ -0,0 +1,26 @@
+package optimus
+
+// Returns n (input, Encode(input)) pairs for use as test fixtures. The
+// inputs are spread evenly across the domain from 0 to MAX_INT so the same
+// seed always gives the same pairs. Returns an empty slice if n is not
+// positive.
+func SampleEncodings(o Optimus, n int) [][2]uint64 {
+	if n < 1 {
+		return [][2]uint64{}
+	}
+
+	pairs := make([][2]uint64, n)
+	var step uint64
+	if n > 1 {
+		step = MAX_INT / uint64(n-1)
+	}
+
+	for i := range pairs {
+		input := uint64(i) * step
+		if i == n-1 && n > 1 {
+			input = MAX_INT
+		}
+		pairs[i] = [2]uint64{input, o.Encode(input)}
+	}
+	return pairs
+}
@@ -0,0 +1,36 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that every sampled pair decodes back to its input.
+func TestSampleEncodings(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, n := range []int{1, 2, 10, 1000} {
+		pairs := SampleEncodings(o, n)
+		if len(pairs) != n {
+			t.Errorf("Expected %d pairs. Got %d", n, len(pairs))
+		}
+
+		seen := make(map[uint64]bool)
+		for _, pair := range pairs {
+			if decoded := o.Decode(pair[1]); decoded != pair[0] {
+				t.Errorf("%d: %d -> %d - FAILED", pair[0], pair[1], decoded)
+			}
+			if seen[pair[0]] {
+				t.Errorf("%d sampled twice", pair[0])
+			}
+			seen[pair[0]] = true
+		}
+	}
+
+	if pairs := SampleEncodings(o, 10); pairs[0][0] != 0 || pairs[9][0] != MAX_INT {
+		t.Errorf("Expected the sample to span the domain. Got %v", pairs)
+	}
+
+	if pairs := SampleEncodings(o, 0); len(pairs) != 0 {
+		t.Errorf("Expected no pairs. Got %v", pairs)
+	}
+}
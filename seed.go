@@ -0,0 +1,313 @@
+package optimus
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"math/bits"
+	"strings"
+	"sync"
+)
+
+// Size in bits of the primes generated by GenerateSeedLocal. Primes are kept
+// below 2^63 for compatibility with versions of New which rejected larger
+// primes.
+const LOCAL_PRIME_BITS = 63
+
+// Primes below this are considered too weak to obfuscate ids. Seed
+// generators such as GenerateSeedLocal never return them and New,
+// NewCalculated and NewWithMinAccuracy log a warning the first time they are
+// given one. Set to 0 to disable. Defaults to 2^32.
+// NB: The primes.utm.edu files used by GenerateSeed and
+// GenerateSeedFromZipFile only contain primes of up to 9 digits, which are
+// all below the default. Those generators can not meet it and still return
+// such primes. Use GenerateSeedLocal for production seeds.
+var MinSecurePrime uint64 = 1 << 32
+
+// Reports whether n is prime. Every constructor, validator and seed
+// generator consults it, so that eg. FIPS environments or hardware
+// accelerators can supply their own test. Defaults to MILLER_RABIN rounds of
+// Miller-Rabin using math/big. NewStrict and GenerateValidatedSeed also
+// apply an independent test of their own.
+// It is read without synchronization, so set it during initialization
+// before any seed is created. It may be called from several goroutines at
+// once (eg. by ValidatePrimes) so it must be safe for concurrent use.
+var PrimalityTest = func(n uint64) bool {
+	return new(big.Int).SetUint64(n).ProbablyPrime(MILLER_RABIN)
+}
+
+// Source of randomness used for seed generation. Tests replace it with a
+// deterministic reader.
+var randReader io.Reader = rand.Reader
+
+// Number of bits of headroom GenerateSeedForCapacity requires between the
+// largest id and the size of the domain.
+const CAPACITY_HEADROOM_BITS = BITS_64 - LIKELY_ID_BITS
+
+// Number of primes GenerateSeedWithPredicate tries before giving up.
+const PREDICATE_ATTEMPTS = 1000
+
+// Number of seeds GenerateSeedAvoiding tries before giving up.
+const AVOIDING_ATTEMPTS = 100
+
+// Generates a valid Optimus struct without using the network. The prime is
+// generated locally using crypto/rand and the random number is
+// cryptographically secure. Unlike GenerateSeed, this is available in
+// builds using the optimus_no_network tag. Primes below MinSecurePrime are
+// discarded, giving up after VALIDATION_ATTEMPTS tries.
+func GenerateSeedLocal() (*Optimus, error) {
+	for i := 0; i < VALIDATION_ATTEMPTS; i++ {
+		o, err := GenerateSeedFrom(LocalPrimeSource{})
+		if err != nil || o.prime >= MinSecurePrime {
+			return o, err
+		}
+	}
+	return nil, jsonerror.New(1, "Could not generate seed", fmt.Sprintf("No prime above MinSecurePrime=%d found after %d attempts", MinSecurePrime, VALIDATION_ATTEMPTS))
+}
+
+// Generates a seed locally for a table which will hold at most maxID rows.
+// The domain is fixed at 2^64 so there is no bit width to choose: the
+// capacity is only checked to leave at least CAPACITY_HEADROOM_BITS bits of
+// headroom, so that valid ids stay sparse within the domain. The prime has
+// LOCAL_PRIME_BITS bits so that even small ids are spread across the whole
+// domain. Returns an error if maxID is too large.
+func GenerateSeedForCapacity(maxID uint64) (Optimus, error) {
+	if bits.Len64(maxID) > 64-CAPACITY_HEADROOM_BITS {
+		return Optimus{}, jsonerror.New(12, "Out of domain", fmt.Sprintf("maxID=%d. At most %d bits are supported to leave %d bits of headroom", maxID, 64-CAPACITY_HEADROOM_BITS, CAPACITY_HEADROOM_BITS))
+	}
+
+	o, err := GenerateSeedLocal()
+	if err != nil {
+		return Optimus{}, err
+	}
+	return *o, nil
+}
+
+// Generates a seed locally like GenerateSeedLocal, but with a prime of
+// exactly bits bits which satisfies pred, eg. SafePrimePredicate. Random
+// primes are tried until one satisfies pred, giving up after
+// PREDICATE_ATTEMPTS tries. Primes below MinSecurePrime are never returned.
+// Returns an error if bits is not between 3 and LOCAL_PRIME_BITS, or if
+// every bits-bit prime is below MinSecurePrime (eg. bits <= 32 with the
+// default floor).
+// NB: Predicates which very few primes satisfy, such as being a Mersenne
+// prime, will not be met by a random search. Use DeriveSeed with the prime
+// instead.
+func GenerateSeedWithPredicate(bits int, pred func(uint64) bool) (Optimus, error) {
+	if bits < 3 || bits > LOCAL_PRIME_BITS {
+		return Optimus{}, jsonerror.New(14, "Invalid bit width", fmt.Sprintf("bits=%d. Must be between 3 and %d", bits, LOCAL_PRIME_BITS))
+	}
+
+	if max := uint64(1)<<uint(bits) - 1; max < MinSecurePrime {
+		return Optimus{}, jsonerror.New(14, "Invalid bit width", fmt.Sprintf("bits=%d. Every %d-bit prime is below MinSecurePrime=%d", bits, bits, MinSecurePrime))
+	}
+
+	var b [8]byte
+	for i := 0; i < PREDICATE_ATTEMPTS; i++ {
+		if _, err := io.ReadFull(randReader, b[:]); err != nil {
+			return Optimus{}, jsonerror.New(1, "Could not generate seed", err.Error())
+		}
+
+		prime := nextPrimeWithBits(binary.BigEndian.Uint64(b[:]), uint(bits))
+		if prime >= MinSecurePrime && pred(prime) {
+			return DeriveSeed(prime)
+		}
+	}
+	return Optimus{}, jsonerror.New(1, "Could not generate seed", fmt.Sprintf("No %d-bit prime above MinSecurePrime=%d satisfied the predicate after %d attempts", bits, MinSecurePrime, PREDICATE_ATTEMPTS))
+}
+
+// Generates seeds locally like GenerateSeedLocal until one encodes each of
+// the ids 0 to sampleCheck-1 to a Base62 string (see EncodeToString) which
+// contains none of bannedSubstrings, ignoring case. Gives up after
+// AVOIDING_ATTEMPTS seeds. Returns an error if sampleCheck is not positive
+// or a banned substring is empty.
+// NB: This is probabilistic. Only the sampled ids are checked, so larger
+// ids may still contain a banned substring. Short substrings are found in
+// most encodings, so a seed avoiding them for many ids may not exist.
+func GenerateSeedAvoiding(bannedSubstrings []string, sampleCheck int) (Optimus, error) {
+	if sampleCheck < 1 {
+		return Optimus{}, jsonerror.New(34, "Invalid count", fmt.Sprintf("sampleCheck=%d. Must be positive", sampleCheck))
+	}
+
+	banned := make([]string, len(bannedSubstrings))
+	for i, substring := range bannedSubstrings {
+		if substring == "" {
+			return Optimus{}, jsonerror.New(21, "Empty parameter", fmt.Sprintf("index %d: Banned substring is empty", i))
+		}
+		banned[i] = strings.ToLower(substring)
+	}
+
+	for i := 0; i < AVOIDING_ATTEMPTS; i++ {
+		o, err := GenerateSeedLocal()
+		if err != nil {
+			return Optimus{}, err
+		}
+		if avoidsSubstrings(*o, banned, sampleCheck) {
+			return *o, nil
+		}
+	}
+	return Optimus{}, jsonerror.New(1, "Could not generate seed", fmt.Sprintf("No seed avoided the banned substrings for %d ids after %d attempts", sampleCheck, AVOIDING_ATTEMPTS))
+}
+
+// Reports whether the ids 0 to sampleCheck-1 encode to Base62 strings which
+// contain none of banned, which must be lower case.
+func avoidsSubstrings(o Optimus, banned []string, sampleCheck int) bool {
+	for n := 0; n < sampleCheck; n++ {
+		s := strings.ToLower(o.EncodeToString(uint64(n)))
+		for _, substring := range banned {
+			if strings.Contains(s, substring) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Reports whether the prime p is a safe prime, ie. (p-1)/2 is also prime.
+// For use with GenerateSeedWithPredicate.
+func SafePrimePredicate(p uint64) bool {
+	return p > 2 && isPrime((p-1)/2)
+}
+
+// Returns an Optimus for a prime you have already vetted. The prime is
+// validated, the modInverse is calculated and a cryptographically secure
+// random number is generated.
+func DeriveSeed(prime uint64) (Optimus, error) {
+	if err := validatePrime(prime); err != nil {
+		return Optimus{}, err
+	}
+
+	random, err := randomNumber()
+	if err != nil {
+		return Optimus{}, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+
+	return Optimus{prime: prime, modInverse: ModInverse(prime), random: random}, nil
+}
+
+// Derives an Optimus deterministically from a master secret using HKDF
+// (SHA-256). The context (eg. tenant or environment) is used as the HKDF
+// info parameter so that the same masterKey and context always yield the
+// same seed while distinct contexts yield unrelated seeds. The prime is
+// found by searching upwards from a derived candidate.
+func DeriveFromKey(masterKey []byte, context []byte) (Optimus, error) {
+	if len(masterKey) == 0 {
+		return Optimus{}, jsonerror.New(6, "Invalid master key", "Master key is empty")
+	}
+
+	var b [16]byte
+	if _, err := io.ReadFull(hkdf.New(sha256.New, masterKey, nil, context), b[:]); err != nil {
+		return Optimus{}, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+
+	return seedFromBytes(b), nil
+}
+
+// Derives an Optimus deterministically from a passphrase using scrypt
+// (N=2^15, r=8, p=1) with a fixed salt, so the same passphrase always yields
+// the same seed. The prime is found by searching upwards from a derived
+// candidate. This is a convenience for simple deployments: anyone who
+// guesses the passphrase can rebuild the seed, so it must be long and
+// random. Prefer GenerateSeedLocal and storing the seed where possible.
+func NewFromPassphrase(passphrase string) (Optimus, error) {
+	if passphrase == "" {
+		return Optimus{}, jsonerror.New(21, "Empty parameter", "Passphrase is empty")
+	}
+
+	//Changing these or the salt changes every derived seed
+	key, err := scrypt.Key([]byte(passphrase), []byte("optimus-go passphrase"), 1<<15, 8, 1, 16)
+	if err != nil {
+		return Optimus{}, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+
+	var b [16]byte
+	copy(b[:], key)
+	return seedFromBytes(b), nil
+}
+
+// Builds an Optimus from 16 uniformly distributed bytes. The first 8 pick
+// the prime and the last 8 the random number.
+func seedFromBytes(b [16]byte) Optimus {
+	prime := nextLocalPrime(binary.BigEndian.Uint64(b[:8]))
+	random := binary.BigEndian.Uint64(b[8:])%(MAX_INT-2) + 1
+
+	return Optimus{prime: prime, modInverse: ModInverse(prime), random: random}
+}
+
+// Returns the smallest prime with LOCAL_PRIME_BITS bits which is not less
+// than n after n has been forced into that range. See nextPrimeWithBits.
+func nextLocalPrime(n uint64) uint64 {
+	return nextPrimeWithBits(n, LOCAL_PRIME_BITS)
+}
+
+// Returns the smallest odd prime with exactly bits bits (3 to 63) which is
+// not less than n after n has been forced into that range. The search wraps
+// around to the bottom of the range if it runs past the top.
+func nextPrimeWithBits(n uint64, bits uint) uint64 {
+	bottom := uint64(1) << (bits - 1)
+	top := uint64(1) << bits
+
+	n = (n|bottom)&(top-1) | 1
+	for !isPrime(n) {
+		n += 2
+		if n >= top {
+			n = bottom | 1
+		}
+	}
+	return n
+}
+
+// Returns an error if n can not be used as the prime of an Optimus.
+func validatePrime(n uint64) error {
+	if n == 2 {
+		return ErrEvenPrime
+	}
+	if !isPrime(n) {
+		return notPrimeError(n)
+	}
+	return nil
+}
+
+// Reports whether n passes PrimalityTest.
+func isPrime(n uint64) bool {
+	return PrimalityTest(n)
+}
+
+// Ensures warnInsecurePrime logs at most once per process.
+var warnInsecureOnce sync.Once
+
+// Logs a warning the first time a prime below MinSecurePrime is seen, so
+// that constructing seeds repeatedly does not flood the log.
+func warnInsecurePrime(prime uint64) {
+	if prime >= MinSecurePrime {
+		return
+	}
+	warnInsecureOnce.Do(func() {
+		log.Printf("\x1b[31mWARNING: Optimus prime %d is below MinSecurePrime (%d). Small primes are easily reversed! Further warnings are suppressed.\x1b[39;49m", prime, MinSecurePrime)
+	})
+}
+
+// Returns the error used when n fails the primality test.
+func notPrimeError(n uint64) error {
+	accuracy := 1.0 - 1.0/math.Pow(float64(4), float64(MILLER_RABIN))
+	return jsonerror.New(2, "Number is not prime", fmt.Sprintf("n=%d. %d Miller-Rabin tests done. Accuracy: %f", n, MILLER_RABIN, accuracy))
+}
+
+// Generates a cryptographically secure random number between 1 and
+// MAX_INT - 2.
+func randomNumber() (uint64, error) {
+	upper := new(big.Int).SetUint64(MAX_INT - 2)
+	n, err := rand.Int(randReader, upper)
+	if err != nil {
+		return 0, err
+	}
+	return n.Uint64() + 1, nil
+}
@@ -0,0 +1,385 @@
+package optimus
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"log"
+	"math/big"
+	"math/bits"
+	mathrand "math/rand"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Tests that DeriveSeed preserves the prime, calculates a valid modInverse
+// and produces an Optimus which round-trips.
+func TestDeriveSeed(t *testing.T) {
+	for _, prime := range []uint64{1580030173, 2123809381, 4294967291} {
+		o, err := DeriveSeed(prime)
+		if err != nil {
+			t.Errorf("DeriveSeed(%d) - FAILED: %v", prime, err)
+			continue
+		}
+
+		if o.Prime() != prime {
+			t.Errorf("Expected prime %d. Got %d", prime, o.Prime())
+		}
+
+		if o.Prime()*o.ModInverse() != 1 {
+			t.Errorf("ModInverse %d is not the inverse of %d", o.ModInverse(), prime)
+		}
+
+		if o.Random() == 0 {
+			t.Errorf("Expected non-zero random for prime %d", prime)
+		}
+
+		for _, value := range []uint64{0, 1, 15, 1 << 40, MAX_INT} {
+			if got := o.Decode(o.Encode(value)); got != value {
+				t.Errorf("%d: -> %d - FAILED", value, got)
+			}
+		}
+	}
+}
+
+// Tests that DeriveSeed rejects composite numbers.
+func TestDeriveSeedNotPrime(t *testing.T) {
+	for _, n := range []uint64{0, 1, 4, 1580030175} {
+		if _, err := DeriveSeed(n); err == nil {
+			t.Errorf("Expected DeriveSeed(%d) to fail", n)
+		}
+	}
+}
+
+// Tests that GenerateSeedLocal produces a prime of the expected size and an
+// Optimus which round-trips.
+func TestGenerateSeedLocal(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		o, err := GenerateSeedLocal()
+		if err != nil {
+			t.Errorf("Try %d - Failed: %v", i, err)
+			continue
+		}
+
+		if !isPrime(o.Prime()) || o.Prime() < 1<<(LOCAL_PRIME_BITS-1) || o.Prime() >= 1<<LOCAL_PRIME_BITS {
+			t.Errorf("Try %d - Failed - Obtained Prime:%d is not a %d-bit Prime", i, o.Prime(), LOCAL_PRIME_BITS)
+		}
+
+		for _, value := range []uint64{0, 15, MAX_INT} {
+			if got := o.Decode(o.Encode(value)); got != value {
+				t.Errorf("Try %d - %d: -> %d - FAILED", i, value, got)
+			}
+		}
+	}
+}
+
+// Tests that DeriveFromKey is deterministic for the same key and context and
+// produces distinct seeds for distinct contexts or keys.
+func TestDeriveFromKey(t *testing.T) {
+	key := []byte("correct horse battery staple")
+
+	a, err := DeriveFromKey(key, []byte("tenant-a"))
+	if err != nil {
+		t.Fatalf("DeriveFromKey - FAILED: %v", err)
+	}
+	again, _ := DeriveFromKey(key, []byte("tenant-a"))
+	b, _ := DeriveFromKey(key, []byte("tenant-b"))
+	other, _ := DeriveFromKey([]byte("another master key"), []byte("tenant-a"))
+
+	if a != again {
+		t.Errorf("Expected same key and context to yield the same seed. Got %v and %v", a, again)
+	}
+
+	if a == b {
+		t.Errorf("Expected distinct contexts to yield distinct seeds. Got %v", a)
+	}
+
+	if a == other {
+		t.Errorf("Expected distinct keys to yield distinct seeds. Got %v", a)
+	}
+
+	for _, o := range []Optimus{a, b, other} {
+		if !isPrime(o.Prime()) {
+			t.Errorf("Derived Prime:%d is not a Prime", o.Prime())
+		}
+		if got := o.Decode(o.Encode(15)); got != 15 {
+			t.Errorf("15: -> %d - FAILED", got)
+		}
+	}
+
+	if _, err := DeriveFromKey(nil, []byte("tenant-a")); err == nil {
+		t.Errorf("Expected empty master key to be rejected")
+	}
+}
+
+// Tests that the sampled encodings of a seed from GenerateSeedAvoiding
+// contain none of the banned substrings, in any case.
+func TestGenerateSeedAvoiding(t *testing.T) {
+	banned := []string{"ab", "Z9", "xQ"}
+
+	o, err := GenerateSeedAvoiding(banned, 20)
+	if err != nil {
+		t.Fatalf("GenerateSeedAvoiding - FAILED: %v", err)
+	}
+	if o.Prime() < MinSecurePrime || o.Decode(o.Encode(15)) != 15 {
+		t.Errorf("Invalid seed %v", o)
+	}
+
+	for n := uint64(0); n < 20; n++ {
+		s := strings.ToLower(o.EncodeToString(n))
+		for _, substring := range banned {
+			if strings.Contains(s, strings.ToLower(substring)) {
+				t.Errorf("%d encodes to %s which contains %s", n, o.EncodeToString(n), substring)
+			}
+		}
+	}
+
+	//Nearly every encoding contains one of these
+	if _, err := GenerateSeedAvoiding([]string{"a", "b", "c", "d"}, 100); err == nil {
+		t.Errorf("Expected no seed to be found")
+	}
+
+	if _, err := GenerateSeedAvoiding(banned, 0); err == nil {
+		t.Errorf("Expected a non-positive sampleCheck to be rejected")
+	}
+	if _, err := GenerateSeedAvoiding([]string{"ab", ""}, 20); err == nil {
+		t.Errorf("Expected an empty substring to be rejected")
+	}
+}
+
+// Tests that NewFromPassphrase is deterministic and produces distinct seeds
+// for distinct passphrases.
+func TestNewFromPassphrase(t *testing.T) {
+	a, err := NewFromPassphrase("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("NewFromPassphrase - FAILED: %v", err)
+	}
+	again, _ := NewFromPassphrase("correct horse battery staple")
+	other, _ := NewFromPassphrase("correct horse battery stapler")
+
+	if a != again {
+		t.Errorf("Expected the same passphrase to yield the same seed. Got %v and %v", a, again)
+	}
+
+	if a == other || a.Prime() == other.Prime() || a.Random() == other.Random() {
+		t.Errorf("Expected distinct passphrases to yield distinct seeds. Got %v and %v", a, other)
+	}
+
+	for _, o := range []Optimus{a, other} {
+		if !isPrime(o.Prime()) || o.Prime()*o.ModInverse() != 1 {
+			t.Errorf("Invalid seed %v", o)
+		}
+		if got := o.Decode(o.Encode(15)); got != 15 {
+			t.Errorf("15: -> %d - FAILED", got)
+		}
+	}
+
+	if _, err := NewFromPassphrase(""); err == nil {
+		t.Errorf("Expected an empty passphrase to be rejected")
+	}
+}
+
+// Tests that GenerateSeedForCapacity leaves headroom above the capacity and
+// round-trips the largest id.
+func TestGenerateSeedForCapacity(t *testing.T) {
+	for _, maxID := range []uint64{0, 1000, 1 << 32, 1<<48 - 1} {
+		o, err := GenerateSeedForCapacity(maxID)
+		if err != nil {
+			t.Errorf("GenerateSeedForCapacity(%d) - FAILED: %v", maxID, err)
+			continue
+		}
+
+		headroom := new(big.Int).Lsh(new(big.Int).SetUint64(maxID+1), CAPACITY_HEADROOM_BITS)
+		if Keyspace(o).Cmp(headroom) < 0 {
+			t.Errorf("Keyspace %s does not leave headroom above %d", Keyspace(o), maxID)
+		}
+
+		if got := o.Decode(o.Encode(maxID)); got != maxID {
+			t.Errorf("%d: -> %d - FAILED", maxID, got)
+		}
+	}
+}
+
+// Tests that GenerateSeedForCapacity rejects capacities without headroom.
+func TestGenerateSeedForCapacityTooLarge(t *testing.T) {
+	for _, maxID := range []uint64{1 << 48, MAX_INT} {
+		if _, err := GenerateSeedForCapacity(maxID); err == nil {
+			t.Errorf("Expected %d to be rejected", maxID)
+		}
+	}
+}
+
+// Replaces randReader with a deterministic reader seeded with seed. Returns
+// a function which restores crypto/rand.
+func deterministicRand(seed int64) func() {
+	original := randReader
+	randReader = mathrand.New(mathrand.NewSource(seed))
+	return func() { randReader = original }
+}
+
+// Tests that GenerateSeedLocal is deterministic when randReader is replaced.
+func TestGenerateSeedLocalDeterministic(t *testing.T) {
+	restore := deterministicRand(1)
+	o, err := GenerateSeedLocal()
+	restore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Optimus{prime: 5980212987775051159, modInverse: 6927883728454576679, random: 1603104512986455411}
+	if *o != expected {
+		t.Errorf("Expected %v. Got %v", expected, *o)
+	}
+
+	restore = deterministicRand(1)
+	again, err := GenerateSeedLocal()
+	restore()
+	if err != nil || *again != *o {
+		t.Errorf("Expected %v. Got %v (%v)", *o, *again, err)
+	}
+
+	if randReader != rand.Reader {
+		t.Errorf("Expected randReader to be restored")
+	}
+}
+
+// Tests that a failing randReader returns an error instead of panicking.
+func TestGenerateSeedFailingRand(t *testing.T) {
+	defer func(original io.Reader) { randReader = original }(randReader)
+	randReader = bytes.NewReader(nil)
+
+	if _, err := GenerateSeedLocal(); err == nil {
+		t.Errorf("GenerateSeedLocal: Expected an error - FAILED")
+	}
+
+	//An even number of candidates needs a random tie-break
+	if _, err := GenerateSeedFrom(StubPrimeSource{7907, 7919, 7927, 7933}); err == nil {
+		t.Errorf("GenerateSeedFrom: Expected an error - FAILED")
+	}
+}
+
+// Tests that GenerateSeedLocal never returns a prime below MinSecurePrime.
+func TestGenerateSeedLocalMinSecurePrime(t *testing.T) {
+	defer func(floor uint64) { MinSecurePrime = floor }(MinSecurePrime)
+
+	//Half of the LOCAL_PRIME_BITS primes are below this floor
+	MinSecurePrime = 3 << (LOCAL_PRIME_BITS - 2)
+	for i := 0; i < 20; i++ {
+		o, err := GenerateSeedLocal()
+		if err == nil && o.Prime() < MinSecurePrime {
+			t.Errorf("%d is below the floor - FAILED", o.Prime())
+		}
+	}
+
+	//No LOCAL_PRIME_BITS prime can reach this floor
+	MinSecurePrime = 1 << LOCAL_PRIME_BITS
+	if o, err := GenerateSeedLocal(); err == nil {
+		t.Errorf("Expected an error. Got %d", o.Prime())
+	}
+}
+
+// Tests that GenerateSeedWithPredicate returns primes of the requested size
+// which satisfy the predicate.
+func TestGenerateSeedWithPredicate(t *testing.T) {
+	for _, width := range []int{40, LOCAL_PRIME_BITS} {
+		o, err := GenerateSeedWithPredicate(width, SafePrimePredicate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := o.Prime(); bits.Len64(p) != width || !isPrime(p) || !isPrime((p-1)/2) {
+			t.Errorf("%d: %d is not a %d-bit safe prime - FAILED", width, p, width)
+		}
+		if got := o.Decode(o.Encode(15)); got != 15 {
+			t.Errorf("15: -> %d - FAILED", got)
+		}
+	}
+
+	o, err := GenerateSeedWithPredicate(48, func(p uint64) bool { return p%8 == 3 })
+	if err != nil || o.Prime()%8 != 3 {
+		t.Errorf("Expected a prime which is 3 modulo 8. Got %d (%v)", o.Prime(), err)
+	}
+
+	if _, err := GenerateSeedWithPredicate(48, func(p uint64) bool { return false }); err == nil {
+		t.Errorf("Expected an unsatisfiable predicate to fail")
+	}
+
+	//Every 20 or 32-bit prime is below MinSecurePrime, so the width is
+	//rejected before any prime is tried
+	calls := 0
+	counting := func(p uint64) bool { calls++; return true }
+	for _, width := range []int{20, 32} {
+		if _, err := GenerateSeedWithPredicate(width, counting); err == nil {
+			t.Errorf("Expected %d bits to be rejected below MinSecurePrime", width)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("Expected the width to be rejected up front. Predicate called %d times", calls)
+	}
+
+	if o, err := GenerateSeedWithPredicate(33, counting); err != nil || o.Prime() < MinSecurePrime {
+		t.Errorf("Expected a 33-bit prime above MinSecurePrime. Got %d (%v)", o.Prime(), err)
+	}
+
+	//A lower floor admits narrower widths
+	defer func(floor uint64) { MinSecurePrime = floor }(MinSecurePrime)
+	MinSecurePrime = 1 << 19
+	if o, err := GenerateSeedWithPredicate(20, counting); err != nil || bits.Len64(o.Prime()) != 20 {
+		t.Errorf("Expected a 20-bit prime. Got %d (%v)", o.Prime(), err)
+	}
+
+	for _, width := range []int{-1, 0, 2, LOCAL_PRIME_BITS + 1} {
+		if _, err := GenerateSeedWithPredicate(width, SafePrimePredicate); err == nil {
+			t.Errorf("Expected %d bits to be rejected", width)
+		}
+	}
+}
+
+// Tests SafePrimePredicate on small primes.
+func TestSafePrimePredicate(t *testing.T) {
+	for p, safe := range map[uint64]bool{2: false, 3: false, 5: true, 7: true, 11: true, 13: false, 23: true, 1580030173: false} {
+		if SafePrimePredicate(p) != safe {
+			t.Errorf("%d: Expected %t - FAILED", p, safe)
+		}
+	}
+}
+
+// Tests that New warns about primes below MinSecurePrime.
+func TestMinSecurePrimeWarning(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	New(9223372036854775783, ModInverse(9223372036854775783), 1163945558)
+	if buf.Len() != 0 {
+		t.Errorf("Unexpected warning: %s", buf)
+	}
+
+	warnInsecureOnce = sync.Once{}
+	New(1580030173, 59260789, 1163945558)
+	if !strings.Contains(buf.String(), "MinSecurePrime") {
+		t.Errorf("Expected a warning for 1580030173")
+	}
+}
+
+// Tests that repeatedly constructing seeds with weak primes only logs one
+// warning per process.
+func TestMinSecurePrimeWarningOnce(t *testing.T) {
+	buf := new(bytes.Buffer)
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	warnInsecureOnce = sync.Once{}
+	for _, prime := range []uint64{2147483587, 2147483629} {
+		for i := 0; i < 10; i++ {
+			New(prime, ModInverse(prime), 1163945558)
+			NewCalculated(prime, 1163945558)
+			NewWithMinAccuracy(prime, 1163945558, 0.99)
+		}
+	}
+
+	if count := strings.Count(buf.String(), "MinSecurePrime"); count != 1 {
+		t.Errorf("Expected 1 warning. Got %d:\n%s", count, buf)
+	}
+}
@@ -0,0 +1,44 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Packs shard into the top shardBits bits and local into the remaining
+// 64 - shardBits bits, then encodes the composite. shardBits must be between
+// 1 and 16. Returns an error if shard or local do not fit in their bits.
+func (this Optimus) EncodeSharded(shard uint16, local uint64, shardBits uint8) (uint64, error) {
+	if err := checkShardBits(shardBits); err != nil {
+		return 0, err
+	}
+
+	localBits := 64 - uint(shardBits)
+	if uint64(shard)>>shardBits != 0 {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("shard=%d. Must fit in %d bits", shard, shardBits))
+	}
+	if local>>localBits != 0 {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("local=%d. Must fit in %d bits", local, localBits))
+	}
+
+	return this.Encode(uint64(shard)<<localBits | local), nil
+}
+
+// Decodes a value produced by EncodeSharded with the same shardBits and
+// returns the shard and local parts.
+func (this Optimus) DecodeSharded(n uint64, shardBits uint8) (uint16, uint64, error) {
+	if err := checkShardBits(shardBits); err != nil {
+		return 0, 0, err
+	}
+
+	localBits := 64 - uint(shardBits)
+	composite := this.Decode(n)
+	return uint16(composite >> localBits), composite & (MAX_INT >> shardBits), nil
+}
+
+func checkShardBits(shardBits uint8) error {
+	if shardBits < 1 || shardBits > 16 {
+		return jsonerror.New(14, "Invalid bit width", fmt.Sprintf("shardBits=%d. Must be between 1 and 16", shardBits))
+	}
+	return nil
+}
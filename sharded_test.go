@@ -0,0 +1,58 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that the shard and local parts survive a round-trip.
+func TestEncodeSharded(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, shardBits := range []uint8{1, 10, 16} {
+		maxShard := uint16(1<<shardBits - 1)
+		maxLocal := uint64(MAX_INT) >> shardBits
+
+		for _, shard := range []uint16{0, 1, maxShard} {
+			for _, local := range []uint64{0, 15, maxLocal} {
+				encoded, err := o.EncodeSharded(shard, local, shardBits)
+				if err != nil {
+					t.Errorf("%d:%d/%d - FAILED: %v", shard, local, shardBits, err)
+					continue
+				}
+
+				s, l, err := o.DecodeSharded(encoded, shardBits)
+				if err != nil || s != shard || l != local {
+					t.Errorf("%d:%d/%d: %d -> %d:%d (%v) - FAILED", shard, local, shardBits, encoded, s, l, err)
+				}
+			}
+		}
+	}
+}
+
+// Tests that parts which do not fit and invalid widths are rejected.
+func TestEncodeShardedInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	tests := []struct {
+		shard     uint16
+		local     uint64
+		shardBits uint8
+	}{
+		{0, 0, 0},        //no shard bits
+		{0, 0, 17},       //more bits than a uint16
+		{4, 0, 2},        //shard too large
+		{0, 1 << 62, 2},  //local too large
+		{0, MAX_INT, 16}, //local too large
+		{1 << 15, 0, 15}, //shard too large
+	}
+
+	for _, test := range tests {
+		if _, err := o.EncodeSharded(test.shard, test.local, test.shardBits); err == nil {
+			t.Errorf("Expected %v to be rejected", test)
+		}
+	}
+
+	if _, _, err := o.DecodeSharded(0, 17); err == nil {
+		t.Errorf("Expected shardBits 17 to be rejected")
+	}
+}
@@ -0,0 +1,62 @@
+package optimus
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Number of Base62 characters used for the tag appended by EncodeSigned.
+// The tag is the first 48 bits of the HMAC-SHA256, which need 9 characters.
+const SIGNATURE_TAG_LEN = 9
+
+// Returned by DecodeSigned when the tag does not match.
+var ErrSignatureInvalid = jsonerror.New(26, "Invalid signature", "The tag does not match the encoded id")
+
+// Encodes n and returns the Base62 representation followed by a truncated
+// HMAC-SHA256 tag of it computed with key, so that tampered or forged ids
+// can be detected. The key should be at least 32 random bytes.
+// Panics if key is empty.
+func (this Optimus) EncodeSigned(n uint64, key []byte) string {
+	if len(key) == 0 {
+		panic(jsonerror.New(19, "Invalid key", "Key is empty"))
+	}
+
+	s := base62Encode(this.Encode(n))
+	return s + signatureTag(s, key)
+}
+
+// Verifies the tag of a string produced by EncodeSigned in constant time
+// and then decodes it. Returns ErrSignatureInvalid if the tag does not
+// match.
+func (this Optimus) DecodeSigned(s string, key []byte) (uint64, error) {
+	if len(key) == 0 {
+		return 0, jsonerror.New(19, "Invalid key", "Key is empty")
+	}
+
+	if len(s) <= SIGNATURE_TAG_LEN {
+		return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q is too short to contain a tag", s))
+	}
+
+	body, tag := s[:len(s)-SIGNATURE_TAG_LEN], s[len(s)-SIGNATURE_TAG_LEN:]
+	if !hmac.Equal([]byte(tag), []byte(signatureTag(body, key))) {
+		return 0, ErrSignatureInvalid
+	}
+
+	return this.DecodeFromString(body)
+}
+
+// Returns the tag of s as SIGNATURE_TAG_LEN Base62 characters.
+func signatureTag(s string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	sum := mac.Sum(nil)
+
+	tag := []byte(base62Encode(binary.BigEndian.Uint64(sum) >> 16))
+	for len(tag) < SIGNATURE_TAG_LEN {
+		tag = append([]byte{BASE62_ALPHABET[0]}, tag...)
+	}
+	return string(tag)
+}
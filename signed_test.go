@@ -0,0 +1,58 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that signed ids round-trip with the right key.
+func TestEncodeSigned(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	for _, value := range []uint64{0, 15, 1 << 32, MAX_INT} {
+		s := o.EncodeSigned(value, key)
+		if len(s) != len(o.EncodeToString(value))+SIGNATURE_TAG_LEN {
+			t.Errorf("%d: Unexpected length of %s", value, s)
+		}
+
+		n, err := o.DecodeSigned(s, key)
+		if err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, s, n, err)
+		}
+	}
+}
+
+// Tests that tampered ids and the wrong key are rejected.
+func TestDecodeSignedInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	key := []byte("0123456789abcdef0123456789abcdef")
+	s := o.EncodeSigned(15, key)
+
+	//Change each character in turn
+	for i := 0; i < len(s); i++ {
+		b := []byte(s)
+		if b[i] == 'A' {
+			b[i] = 'B'
+		} else {
+			b[i] = 'A'
+		}
+		if _, err := o.DecodeSigned(string(b), key); err != ErrSignatureInvalid {
+			t.Errorf("%s: Expected ErrSignatureInvalid. Got %v", b, err)
+		}
+	}
+
+	if _, err := o.DecodeSigned(s, []byte("fedcba9876543210fedcba9876543210")); err != ErrSignatureInvalid {
+		t.Errorf("Expected wrong key to be rejected. Got %v", err)
+	}
+
+	//An unsigned id is too short or has a bad tag
+	for _, unsigned := range []string{"", o.EncodeToString(15), o.EncodeToString(MAX_INT)} {
+		if _, err := o.DecodeSigned(unsigned, key); err == nil {
+			t.Errorf("Expected %q to be rejected", unsigned)
+		}
+	}
+
+	if _, err := o.DecodeSigned(s, nil); err == nil {
+		t.Errorf("Expected empty key to be rejected")
+	}
+}
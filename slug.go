@@ -0,0 +1,53 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"strings"
+)
+
+// Encodes n and returns the Base62 representation with sep inserted every
+// groupSize characters, eg. "ab-cd-ef". Panics if groupSize is not positive
+// or sep is empty or contains Base62 characters.
+func (this Optimus) EncodeSlug(n uint64, groupSize int, sep string) string {
+	if err := checkSlugFormat(groupSize, sep); err != nil {
+		panic(err)
+	}
+	return groupSlug(this.EncodeToString(n), groupSize, sep)
+}
+
+// Strips every sep from a slug produced by EncodeSlug and decodes the
+// result. If strict is true the separators must be exactly where EncodeSlug
+// puts them for groupSize. Returns an error if the format is invalid or
+// the slug is malformed.
+func (this Optimus) DecodeSlug(s string, groupSize int, sep string, strict bool) (uint64, error) {
+	if err := checkSlugFormat(groupSize, sep); err != nil {
+		return 0, err
+	}
+
+	stripped := strings.Replace(s, sep, "", -1)
+	if strict && groupSlug(stripped, groupSize, sep) != s {
+		return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q has misplaced separators", s))
+	}
+	return this.DecodeFromString(stripped)
+}
+
+// Inserts sep every groupSize characters of s.
+func groupSlug(s string, groupSize int, sep string) string {
+	var groups []string
+	for len(s) > groupSize {
+		groups = append(groups, s[:groupSize])
+		s = s[groupSize:]
+	}
+	return strings.Join(append(groups, s), sep)
+}
+
+func checkSlugFormat(groupSize int, sep string) error {
+	if groupSize < 1 {
+		return jsonerror.New(27, "Invalid slug format", fmt.Sprintf("groupSize=%d. Must be positive", groupSize))
+	}
+	if sep == "" || strings.ContainsAny(sep, BASE62_ALPHABET) {
+		return jsonerror.New(27, "Invalid slug format", fmt.Sprintf("sep=%q. Must be non-empty and contain no Base62 characters", sep))
+	}
+	return nil
+}
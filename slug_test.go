@@ -0,0 +1,70 @@
+package optimus
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests that slugs round-trip with various group sizes and separators.
+func TestEncodeSlug(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, groupSize := range []int{1, 2, 3, 11, 20} {
+		for _, sep := range []string{"-", "_", "~~"} {
+			for _, value := range []uint64{0, 15, MAX_INT} {
+				s := o.EncodeSlug(value, groupSize, sep)
+
+				if stripped := strings.Replace(s, sep, "", -1); stripped != o.EncodeToString(value) {
+					t.Errorf("%s: Expected %s once separators are removed", s, o.EncodeToString(value))
+				}
+
+				for _, strict := range []bool{false, true} {
+					n, err := o.DecodeSlug(s, groupSize, sep, strict)
+					if err != nil || n != value {
+						t.Errorf("%d/%d/%q: %s -> %d (%v) - FAILED", value, groupSize, sep, s, n, err)
+					}
+				}
+			}
+		}
+	}
+
+	if s := o.EncodeSlug(MAX_INT, 4, "-"); len(s) != 11+2 || s[4] != '-' || s[9] != '-' {
+		t.Errorf("Unexpected slug %s", s)
+	}
+}
+
+// Tests that misplaced separators are only rejected in strict mode and that
+// invalid formats are rejected.
+func TestDecodeSlugInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	s := o.EncodeSlug(MAX_INT, 4, "-")
+	misplaced := strings.Replace(s, "-", "", -1)[:3] + "-" + strings.Replace(s, "-", "", -1)[3:]
+
+	if n, err := o.DecodeSlug(misplaced, 4, "-", false); err != nil || n != MAX_INT {
+		t.Errorf("Expected lenient mode to accept %s. Got %d (%v)", misplaced, n, err)
+	}
+
+	for _, bad := range []string{misplaced, s + "-", "-" + s, strings.Replace(s, "-", "--", 1)} {
+		if _, err := o.DecodeSlug(bad, 4, "-", true); err == nil {
+			t.Errorf("Expected strict mode to reject %s", bad)
+		}
+	}
+
+	for _, format := range []struct {
+		groupSize int
+		sep       string
+	}{{0, "-"}, {-1, "-"}, {4, ""}, {4, "a"}, {4, "-0"}} {
+		if _, err := o.DecodeSlug(s, format.groupSize, format.sep, false); err == nil {
+			t.Errorf("Expected %v to be rejected", format)
+		}
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected EncodeSlug to panic for %v", format)
+				}
+			}()
+			o.EncodeSlug(15, format.groupSize, format.sep)
+		}()
+	}
+}
@@ -0,0 +1,165 @@
+package optimus
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"io"
+	"math/big"
+)
+
+// PrimeSource provides candidate prime numbers for seed generation.
+// Candidates are not trusted: GenerateSeedFrom validates the one it picks.
+type PrimeSource interface {
+	Primes() ([]uint64, error)
+}
+
+// TraceInfo describes how a prime was obtained from a NetworkPrimeSource.
+type TraceInfo struct {
+	File        uint8    // The zip file identifier
+	URL         string   // The URL the zip file was downloaded from
+	WindowStart uint64   // Start of the byte window that was parsed
+	WindowEnd   uint64   // End of the byte window that was parsed
+	Candidates  []uint64 // Numbers parsed from the window
+	Prime       uint64   // The candidate selected by GenerateSeedFrom
+}
+
+// Implemented by sources which want to know which candidate was selected.
+type selectionObserver interface {
+	selected(prime uint64)
+}
+
+// LocalPrimeSource generates a single prime of LOCAL_PRIME_BITS bits
+// locally using crypto/rand.
+type LocalPrimeSource struct{}
+
+// Returns a single locally generated prime. The prime is the next prime
+// after a random starting point.
+func (this LocalPrimeSource) Primes() ([]uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(randReader, b[:]); err != nil {
+		return nil, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+	return []uint64{nextLocalPrime(binary.BigEndian.Uint64(b[:]))}, nil
+}
+
+// StubPrimeSource returns a fixed list of candidates. It is intended for
+// tests which need deterministic seed generation.
+type StubPrimeSource []uint64
+
+// Returns the stubbed candidates.
+func (this StubPrimeSource) Primes() ([]uint64, error) {
+	return this, nil
+}
+
+// Generates a valid Optimus struct using a prime picked from the candidates
+// returned by src. If there are more than 2 candidates the middle one is
+// picked, otherwise the largest. Returns an error if src fails, returns no
+// candidates or the picked candidate is not prime.
+func GenerateSeedFrom(src PrimeSource) (*Optimus, error) {
+	selectedNumbers, err := src.Primes()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(selectedNumbers) == 0 {
+		return nil, jsonerror.New(1, "Could not generate seed", "No candidate primes found")
+	}
+
+	//Not perfect but good enough
+
+	var selectedPrime uint64
+	length := len(selectedNumbers)
+	if length > 2 {
+		//Pick middle number
+
+		//Check if length is even number
+		//Check if round is odd or even
+		var odd bool
+		if length&1 != 0 {
+			odd = true //odd
+		} else {
+			odd = false //even
+		}
+
+		if odd {
+			selectedPrime = selectedNumbers[length/2]
+		} else {
+
+			r := *big.NewInt(1)
+			rn, err := rand.Int(randReader, &r)
+			if err != nil {
+				return nil, jsonerror.New(1, "Could not generate seed", err.Error())
+			}
+			if rn.Uint64() == 0 {
+				selectedPrime = selectedNumbers[length/2]
+			} else {
+				selectedPrime = selectedNumbers[length/2-1]
+			}
+		}
+	} else {
+		//Pick largest number
+		largest := selectedNumbers[0]
+
+		for _, value := range selectedNumbers {
+			if value > largest {
+				largest = value
+			}
+		}
+
+		selectedPrime = largest
+	}
+
+	if observer, ok := src.(selectionObserver); ok {
+		observer.selected(selectedPrime)
+	}
+
+	if err := validatePrime(selectedPrime); err != nil {
+		return nil, err
+	}
+
+	//Calculate Mod Inverse for selectedPrime
+	modInverse := ModInverse(selectedPrime)
+
+	//Generate Random Integer less than MAX_INT
+	random, err := randomNumber()
+	if err != nil {
+		return nil, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+
+	return &Optimus{prime: selectedPrime, modInverse: modInverse, random: random}, nil
+}
+
+// Returns up to count distinct candidates from src which are prime, in the
+// order src returned them, so that the caller can pick the prime instead of
+// GenerateSeedFrom. Returns an error if count is not positive, if src fails
+// or if none of the candidates are prime.
+func CandidatePrimesFrom(src PrimeSource, count int) ([]uint64, error) {
+	if count < 1 {
+		return nil, jsonerror.New(34, "Invalid count", fmt.Sprintf("count=%d. Must be positive", count))
+	}
+
+	candidates, err := src.Primes()
+	if err != nil {
+		return nil, err
+	}
+
+	var primes []uint64
+	seen := make(map[uint64]bool, len(candidates))
+	for _, candidate := range candidates {
+		if len(primes) == count {
+			break
+		}
+		if seen[candidate] || validatePrime(candidate) != nil {
+			continue
+		}
+		seen[candidate] = true
+		primes = append(primes, candidate)
+	}
+
+	if len(primes) == 0 {
+		return nil, jsonerror.New(1, "Could not generate seed", "No candidate primes found")
+	}
+	return primes, nil
+}
@@ -0,0 +1,89 @@
+package optimus
+
+import (
+	"errors"
+	"testing"
+)
+
+type failingPrimeSource struct{}
+
+func (this failingPrimeSource) Primes() ([]uint64, error) {
+	return nil, errors.New("unavailable")
+}
+
+// Tests that GenerateSeedFrom picks the candidate deterministically from a
+// stubbed source.
+func TestGenerateSeedFromStub(t *testing.T) {
+	tests := []struct {
+		candidates StubPrimeSource
+		expected   uint64
+	}{
+		{StubPrimeSource{1580030173}, 1580030173},
+		{StubPrimeSource{2123809381, 1580030173}, 2123809381},             //largest of 2
+		{StubPrimeSource{1580030141, 1580030173, 1580030177}, 1580030173}, //middle of 3
+	}
+
+	for _, test := range tests {
+		o, err := GenerateSeedFrom(test.candidates)
+		if err != nil {
+			t.Errorf("%v - FAILED: %v", test.candidates, err)
+			continue
+		}
+
+		if o.Prime() != test.expected {
+			t.Errorf("%v: expected prime %d. Got %d", test.candidates, test.expected, o.Prime())
+		}
+
+		if o.ModInverse() != ModInverse(test.expected) {
+			t.Errorf("%v: expected modInverse %d. Got %d", test.candidates, ModInverse(test.expected), o.ModInverse())
+		}
+
+		if got := o.Decode(o.Encode(15)); got != 15 {
+			t.Errorf("15: -> %d - FAILED", got)
+		}
+	}
+}
+
+// Tests that GenerateSeedFrom reports failures from the source, empty
+// sources and composite candidates.
+func TestGenerateSeedFromInvalid(t *testing.T) {
+	for _, src := range []PrimeSource{failingPrimeSource{}, StubPrimeSource{}, StubPrimeSource{1580030175}} {
+		if _, err := GenerateSeedFrom(src); err == nil {
+			t.Errorf("Expected %v to fail", src)
+		}
+	}
+}
+
+// Tests that CandidatePrimesFrom skips composites and duplicates and stops
+// at count.
+func TestCandidatePrimesFrom(t *testing.T) {
+	src := StubPrimeSource{1580030175, 1580030131, 2, 1580030131, 1580030173, 1580030209}
+
+	primes, err := CandidatePrimesFrom(src, 10)
+	if err != nil || len(primes) != 3 || primes[0] != 1580030131 || primes[1] != 1580030173 || primes[2] != 1580030209 {
+		t.Errorf("Unexpected candidates %v (%v)", primes, err)
+	}
+
+	primes, err = CandidatePrimesFrom(src, 1)
+	if err != nil || len(primes) != 1 || primes[0] != 1580030131 {
+		t.Errorf("Unexpected candidates %v (%v)", primes, err)
+	}
+
+	for _, src := range []PrimeSource{failingPrimeSource{}, StubPrimeSource{}, StubPrimeSource{1580030175, 2}} {
+		if _, err := CandidatePrimesFrom(src, 10); err == nil {
+			t.Errorf("Expected %v to fail", src)
+		}
+	}
+
+	if _, err := CandidatePrimesFrom(src, 0); err == nil {
+		t.Errorf("Expected a count of 0 to be rejected")
+	}
+}
+
+// Tests that LocalPrimeSource returns a prime.
+func TestLocalPrimeSource(t *testing.T) {
+	primes, err := LocalPrimeSource{}.Primes()
+	if err != nil || len(primes) != 1 || !isPrime(primes[0]) {
+		t.Errorf("Expected a single prime. Got %v (%v)", primes, err)
+	}
+}
@@ -0,0 +1,62 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Largest domain width accepted by BuildTable.
+const MAX_TABLE_BITS = 20
+
+// LookupTable serves Encode and Decode for a small domain from precomputed
+// arrays instead of arithmetic. See BuildTable.
+type LookupTable struct {
+	encode []uint32
+	decode []uint32
+}
+
+// Precomputes the encodings of every id in the domain [0, 2^bits) for o.
+// Optimus works modulo 2^64, but the low bits of its output only depend on
+// the low bits of its input, so reducing Encode modulo 2^bits gives a
+// bijection on the smaller domain. Returns an error if bits is 0 or above
+// MAX_TABLE_BITS.
+// NB: The table uses 8 * 2^bits bytes: 512KiB for 16 bits and 8MiB for 20
+// bits. It also exposes every (id, encoded) pair of the seed to anything
+// able to read the process memory.
+func BuildTable(o Optimus, bits uint8) (*LookupTable, error) {
+	if bits < 1 || bits > MAX_TABLE_BITS {
+		return nil, jsonerror.New(14, "Invalid bit width", fmt.Sprintf("bits=%d. Must be between 1 and %d", bits, MAX_TABLE_BITS))
+	}
+
+	size := uint64(1) << bits
+	mask := size - 1
+	table := &LookupTable{encode: make([]uint32, size), decode: make([]uint32, size)}
+	for n := uint64(0); n < size; n++ {
+		encoded := o.Encode(n) & mask
+		table.encode[n] = uint32(encoded)
+		table.decode[encoded] = uint32(n)
+	}
+	return table, nil
+}
+
+// Returns the encoding of n. Returns an error if n is larger than Max.
+func (this *LookupTable) Encode(n uint64) (uint64, error) {
+	if n >= uint64(len(this.encode)) {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("n=%d. Must not exceed %d", n, this.Max()))
+	}
+	return uint64(this.encode[n]), nil
+}
+
+// Decodes a number produced by Encode. Returns an error if n is larger than
+// Max.
+func (this *LookupTable) Decode(n uint64) (uint64, error) {
+	if n >= uint64(len(this.decode)) {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("n=%d. Must not exceed %d", n, this.Max()))
+	}
+	return uint64(this.decode[n]), nil
+}
+
+// Returns the largest id in the domain.
+func (this *LookupTable) Max() uint64 {
+	return uint64(len(this.encode)) - 1
+}
@@ -0,0 +1,51 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that the table matches the arithmetic for every value in a 16-bit
+// domain, for both modes.
+func TestBuildTable(t *testing.T) {
+	const mask = 1<<16 - 1
+
+	xor := NewCalculated(1580030173, 1163945558)
+	for _, o := range []Optimus{xor, xor.WithMode(MODE_ADDITIVE)} {
+		table, err := BuildTable(o, 16)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for n := uint64(0); n <= mask; n++ {
+			encoded, err := table.Encode(n)
+			if err != nil || encoded != o.Encode(n)&mask {
+				t.Fatalf("Mode %d: Encode(%d) = %d (%v). Expected %d - FAILED", o.Mode(), n, encoded, err, o.Encode(n)&mask)
+			}
+
+			decoded, err := table.Decode(n)
+			if err != nil || decoded != o.Decode(n)&mask {
+				t.Fatalf("Mode %d: Decode(%d) = %d (%v). Expected %d - FAILED", o.Mode(), n, decoded, err, o.Decode(n)&mask)
+			}
+		}
+
+		if table.Max() != mask {
+			t.Errorf("Expected Max to be %d. Got %d", mask, table.Max())
+		}
+		if _, err := table.Encode(mask + 1); err == nil {
+			t.Errorf("Expected an out of domain id to be rejected")
+		}
+		if _, err := table.Decode(mask + 1); err == nil {
+			t.Errorf("Expected an out of domain encoding to be rejected")
+		}
+	}
+}
+
+// Tests that domains which are too large are rejected.
+func TestBuildTableBits(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	for _, bits := range []uint8{0, MAX_TABLE_BITS + 1, 64} {
+		if _, err := BuildTable(o, bits); err == nil {
+			t.Errorf("Expected %d bits to be rejected", bits)
+		}
+	}
+}
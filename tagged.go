@@ -0,0 +1,68 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Number of high bits reserved for the tag by EncodeTagged.
+const TAG_BITS = 8
+
+// Folds tag into the top TAG_BITS bits of n and encodes the result so that
+// the same id yields unrelated outputs for different id types (eg. users and
+// orders) sharing a seed. Encoding alone would only change the top bits, so
+// the composite is encoded, mixed with FINALIZER_SPLITMIX64 and encoded
+// again. The mixing spreads the tag over every bit and can not be undone
+// without the seed. Returns an error if n does not fit in the remaining
+// bits.
+func (this Optimus) EncodeTagged(tag uint8, n uint64) (uint64, error) {
+	if n>>(64-TAG_BITS) != 0 {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("n=%d. Must fit in %d bits", n, 64-TAG_BITS))
+	}
+	return this.Encode(this.EncodeMixed(uint64(tag)<<(64-TAG_BITS)|n, FINALIZER_SPLITMIX64)), nil
+}
+
+// Decodes a value produced by EncodeTagged. Returns an error if it was
+// encoded with a different tag, so that ids can not be used across types.
+func (this Optimus) DecodeTagged(tag uint8, n uint64) (uint64, error) {
+	actual, local := this.decodeTagged(n)
+	if actual != tag {
+		return 0, jsonerror.New(32, "Tag mismatch", fmt.Sprintf("Expected tag %d. Got %d", tag, actual))
+	}
+	return local, nil
+}
+
+// Reverses EncodeTagged and returns the tag and the id.
+func (this Optimus) decodeTagged(n uint64) (uint8, uint64) {
+	composite := this.DecodeMixed(this.Decode(n), FINALIZER_SPLITMIX64)
+	return uint8(composite >> (64 - TAG_BITS)), composite & (MAX_INT >> TAG_BITS)
+}
+
+// Encodes n with typeID folded into its top TAG_BITS bits like EncodeTagged
+// and returns the Base62 representation (see EncodeToString). Unlike
+// DecodeTagged, DecodeWithType recovers the type, so a field holding ids of
+// several entity types can be dispatched by type. The type is mixed into
+// every bit, so the same id shares no visible bits across types. Panics if
+// n does not fit in 64 - TAG_BITS bits. Use EncodeTagged to get an error
+// instead.
+func (this Optimus) EncodeWithType(typeID uint8, n uint64) string {
+	encoded, err := this.EncodeTagged(typeID, n)
+	if err != nil {
+		panic(err)
+	}
+	return base62Encode(encoded)
+}
+
+// Decodes a string produced by EncodeWithType and returns the type and the
+// id. Returns an error if s is not valid Base62.
+// NB: Every Base62 string decodes to some type and id. Check that the type
+// is one you issue before dispatching on it.
+func (this Optimus) DecodeWithType(s string) (uint8, uint64, error) {
+	encoded, err := base62Decode(s)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	typeID, n := this.decodeTagged(encoded)
+	return typeID, n, nil
+}
@@ -0,0 +1,100 @@
+package optimus
+
+import (
+	"math/bits"
+	"testing"
+)
+
+// Tests that tagged ids round-trip and can not be decoded with another tag.
+func TestEncodeTagged(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint64{0, 15, MAX_INT >> TAG_BITS} {
+		users, err := o.EncodeTagged(1, value)
+		if err != nil {
+			t.Fatalf("%d - FAILED: %v", value, err)
+		}
+		orders, _ := o.EncodeTagged(2, value)
+
+		if users == orders {
+			t.Errorf("%d: Expected different outputs for different tags", value)
+		}
+
+		if n, err := o.DecodeTagged(1, users); err != nil || n != value {
+			t.Errorf("%d: %d -> %d (%v) - FAILED", value, users, n, err)
+		}
+		if n, err := o.DecodeTagged(2, orders); err != nil || n != value {
+			t.Errorf("%d: %d -> %d (%v) - FAILED", value, orders, n, err)
+		}
+
+		if _, err := o.DecodeTagged(2, users); err == nil {
+			t.Errorf("%d: Expected a user id to be rejected as an order id", value)
+		}
+	}
+
+	if _, err := o.EncodeTagged(1, MAX_INT>>TAG_BITS+1); err == nil {
+		t.Errorf("Expected an id using the tag bits to be rejected")
+	}
+}
+
+// Tests that the tag affects every bit, so that the same id can not be
+// linked across tags by comparing the low bits.
+func TestEncodeTaggedLowBits(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	const low = MAX_INT >> TAG_BITS
+
+	var differing int
+	for n := uint64(0); n < 1000; n++ {
+		users, _ := o.EncodeTagged(1, n)
+		orders, _ := o.EncodeTagged(2, n)
+		if users&low == orders&low {
+			t.Errorf("%d: %x and %x share their low bits", n, users, orders)
+		}
+		differing += bits.OnesCount64((users ^ orders) & low)
+
+		typed1, typed2 := o.EncodeWithType(1, n), o.EncodeWithType(2, n)
+		if a, _ := base62Decode(typed1); a != users {
+			t.Errorf("%d: Expected %s to be the Base62 form of %d", n, typed1, users)
+		}
+		if b, _ := base62Decode(typed2); b&low == users&low {
+			t.Errorf("%d: %s and %s share their low bits", n, typed1, typed2)
+		}
+	}
+
+	//About half of the 56 low bits should differ
+	if mean := float64(differing) / 1000; mean < 24 || mean > 32 {
+		t.Errorf("Expected about 28 differing low bits. Got %v", mean)
+	}
+}
+
+// Tests that EncodeWithType round-trips the type and id across several types.
+func TestEncodeWithType(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, typeID := range []uint8{0, 1, 2, 127, 255} {
+		for _, value := range []uint64{0, 15, MAX_INT >> TAG_BITS} {
+			s := o.EncodeWithType(typeID, value)
+
+			tagged, _ := o.EncodeTagged(typeID, value)
+			if s != base62Encode(tagged) {
+				t.Errorf("%d/%d: Expected the Base62 form of EncodeTagged. Got %s", typeID, value, s)
+			}
+
+			decodedType, n, err := o.DecodeWithType(s)
+			if err != nil || decodedType != typeID || n != value {
+				t.Errorf("%d/%d: %s -> %d/%d (%v) - FAILED", typeID, value, s, decodedType, n, err)
+			}
+		}
+	}
+
+	if _, _, err := o.DecodeWithType("!"); err == nil {
+		t.Errorf("Expected invalid Base62 to be rejected")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected an id which does not fit to panic")
+		}
+	}()
+	o.EncodeWithType(1, MAX_INT>>TAG_BITS+1)
+}
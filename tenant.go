@@ -0,0 +1,53 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// TenantResolver picks the Optimus to use for each tenant, eg. from a
+// header or JWT claim, so that per-tenant obfuscation is centralized. It is
+// immutable and safe for concurrent use.
+type TenantResolver struct {
+	seeds map[string]Optimus
+}
+
+// Returns a TenantResolver which uses the Optimus associated with each
+// tenant. The map is copied.
+func NewTenantResolver(seeds map[string]Optimus) TenantResolver {
+	m := make(map[string]Optimus, len(seeds))
+	for tenant, o := range seeds {
+		m[tenant] = o
+	}
+	return TenantResolver{m}
+}
+
+// Returns the Optimus registered for tenant. Returns an error if the
+// tenant is unknown.
+func (this TenantResolver) Seed(tenant string) (Optimus, error) {
+	o, ok := this.seeds[tenant]
+	if !ok {
+		return Optimus{}, jsonerror.New(36, "Unknown tenant", fmt.Sprintf("No Optimus registered for tenant %q", tenant))
+	}
+	return o, nil
+}
+
+// Encodes n using the Optimus registered for tenant. Returns an error if
+// the tenant is unknown.
+func (this TenantResolver) Encode(tenant string, n uint64) (uint64, error) {
+	o, err := this.Seed(tenant)
+	if err != nil {
+		return 0, err
+	}
+	return o.Encode(n), nil
+}
+
+// Decodes n using the Optimus registered for tenant. Returns an error if
+// the tenant is unknown.
+func (this TenantResolver) Decode(tenant string, n uint64) (uint64, error) {
+	o, err := this.Seed(tenant)
+	if err != nil {
+		return 0, err
+	}
+	return o.Decode(n), nil
+}
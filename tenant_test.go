@@ -0,0 +1,71 @@
+package optimus
+
+import (
+	"sync"
+	"testing"
+)
+
+// Tests that each tenant uses its own seed and unknown tenants are
+// rejected.
+func TestTenantResolver(t *testing.T) {
+	acme := NewCalculated(1580030173, 1163945558)
+	globex := NewCalculated(2123809381, 1163945558)
+
+	seeds := map[string]Optimus{"acme": acme, "globex": globex}
+	r := NewTenantResolver(seeds)
+
+	//The map is copied
+	delete(seeds, "globex")
+
+	for tenant, o := range map[string]Optimus{"acme": acme, "globex": globex} {
+		encoded, err := r.Encode(tenant, 15)
+		if err != nil || encoded != o.Encode(15) {
+			t.Errorf("%s: Expected %d. Got %d (%v)", tenant, o.Encode(15), encoded, err)
+		}
+
+		decoded, err := r.Decode(tenant, encoded)
+		if err != nil || decoded != 15 {
+			t.Errorf("%s: %d -> %d (%v) - FAILED", tenant, encoded, decoded, err)
+		}
+	}
+
+	if _, err := r.Encode("initech", 15); err == nil {
+		t.Errorf("Expected an unknown tenant to be rejected by Encode")
+	}
+	if _, err := r.Decode("", 15); err == nil {
+		t.Errorf("Expected an unknown tenant to be rejected by Decode")
+	}
+	if _, err := r.Seed("initech"); err == nil {
+		t.Errorf("Expected an unknown tenant to be rejected by Seed")
+	}
+}
+
+// Tests that the resolver can be used from many goroutines at once.
+// Run with -race.
+func TestTenantResolverConcurrent(t *testing.T) {
+	r := NewTenantResolver(map[string]Optimus{
+		"acme":   NewCalculated(1580030173, 1163945558),
+		"globex": NewCalculated(2123809381, 1163945558),
+	})
+	tenants := []string{"acme", "globex"}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			tenant := tenants[g%2]
+			for n := uint64(0); n < 200; n++ {
+				encoded, err := r.Encode(tenant, n)
+				if err != nil {
+					t.Errorf("%s: %v", tenant, err)
+					return
+				}
+				if decoded, err := r.Decode(tenant, encoded); err != nil || decoded != n {
+					t.Errorf("%s: %d -> %d (%v) - FAILED", tenant, encoded, decoded, err)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,46 @@
+package optimus
+
+// EncodeSteps records the intermediate values of an Encode. See EncodeTrace.
+type EncodeSteps struct {
+	Input      uint64 // n
+	Multiplied uint64 // n * prime, wrapped modulo 2^64
+	Masked     uint64 // Multiplied & MAX_INT. The same as Multiplied since the domain is 2^64
+	Result     uint64 // Masked with the random number applied by XOR or addition depending on the mode
+}
+
+// DecodeSteps records the intermediate values of a Decode. See DecodeTrace.
+type DecodeSteps struct {
+	Input      uint64 // n
+	Randomless uint64 // n with the random number removed by XOR or subtraction depending on the mode
+	Multiplied uint64 // Randomless * modInverse, wrapped modulo 2^64
+	Result     uint64 // Multiplied & MAX_INT
+}
+
+// Encodes n like Encode and also returns each intermediate value. This is a
+// debugging aid for ids which misbehave.
+// WARNING: The steps reveal the seed. Do not log them in production.
+func (this Optimus) EncodeTrace(n uint64) (uint64, EncodeSteps) {
+	steps := EncodeSteps{Input: n}
+	steps.Multiplied = n * this.prime
+	steps.Masked = steps.Multiplied & MAX_INT
+	if this.mode == MODE_ADDITIVE {
+		steps.Result = (steps.Masked + this.random) & MAX_INT
+	} else {
+		steps.Result = steps.Masked ^ this.random
+	}
+	return steps.Result, steps
+}
+
+// Decodes n like Decode and also returns each intermediate value. See
+// EncodeTrace.
+func (this Optimus) DecodeTrace(n uint64) (uint64, DecodeSteps) {
+	steps := DecodeSteps{Input: n}
+	if this.mode == MODE_ADDITIVE {
+		steps.Randomless = n - this.random
+	} else {
+		steps.Randomless = n ^ this.random
+	}
+	steps.Multiplied = steps.Randomless * this.modInverse
+	steps.Result = steps.Multiplied & MAX_INT
+	return steps.Result, steps
+}
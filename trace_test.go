@@ -0,0 +1,45 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that the recorded steps reconstruct the final output and match
+// Encode and Decode for both modes.
+func TestEncodeTrace(t *testing.T) {
+	xor := NewCalculated(1580030173, 1163945558)
+	for _, o := range []Optimus{xor, xor.WithMode(MODE_ADDITIVE)} {
+		for _, value := range []uint64{0, 1, 15, 1 << 63, MAX_INT} {
+			encoded, steps := o.EncodeTrace(value)
+			if encoded != o.Encode(value) || steps.Result != encoded || steps.Input != value {
+				t.Errorf("Mode %d: %d: Expected %d. Got %d (%+v) - FAILED", o.Mode(), value, o.Encode(value), encoded, steps)
+			}
+
+			applied := steps.Masked ^ o.Random()
+			if o.Mode() == MODE_ADDITIVE {
+				applied = steps.Masked + o.Random()
+			}
+			if steps.Multiplied != value*o.Prime() || steps.Masked != steps.Multiplied&MAX_INT || applied != steps.Result {
+				t.Errorf("Mode %d: %d: Inconsistent steps %+v - FAILED", o.Mode(), value, steps)
+			}
+
+			decoded, dsteps := o.DecodeTrace(encoded)
+			if decoded != value || dsteps.Result != value || dsteps.Input != encoded {
+				t.Errorf("Mode %d: %d -> %d (%+v) - FAILED", o.Mode(), encoded, decoded, dsteps)
+			}
+
+			removed := encoded ^ o.Random()
+			if o.Mode() == MODE_ADDITIVE {
+				removed = encoded - o.Random()
+			}
+			if dsteps.Randomless != removed || dsteps.Multiplied != removed*o.ModInverse() || dsteps.Result != dsteps.Multiplied&MAX_INT {
+				t.Errorf("Mode %d: %d: Inconsistent steps %+v - FAILED", o.Mode(), encoded, dsteps)
+			}
+
+			//Decoding mirrors encoding
+			if dsteps.Randomless != steps.Masked {
+				t.Errorf("Mode %d: %d: Expected %d before the random number. Got %d", o.Mode(), value, steps.Masked, dsteps.Randomless)
+			}
+		}
+	}
+}
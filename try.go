@@ -0,0 +1,40 @@
+package optimus
+
+import (
+	"github.com/pjebs/jsonerror"
+)
+
+// RecoveredError is returned by Try. It holds the error the package
+// panicked with.
+type RecoveredError struct {
+	jsonerror.JE
+}
+
+// Reports whether target is a jsonerror with the same code, so that
+// errors.Is(err, ErrNotPrime) works regardless of the details.
+func (this RecoveredError) Is(target error) bool {
+	switch t := target.(type) {
+	case jsonerror.JE:
+		return t.Code == this.Code
+	case RecoveredError:
+		return t.Code == this.Code
+	}
+	return false
+}
+
+// Calls f, typically wrapping New or NewCalculated, and converts a panic
+// raised by this package into a RecoveredError. Panics which did not come
+// from this package are not recovered. Use it while migrating from the
+// panicking constructors to error based handling.
+func Try(f func() Optimus) (o Optimus, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			je, ok := r.(jsonerror.JE)
+			if !ok {
+				panic(r)
+			}
+			o, err = Optimus{}, RecoveredError{je}
+		}
+	}()
+	return f(), nil
+}
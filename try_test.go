@@ -0,0 +1,37 @@
+package optimus
+
+import (
+	"errors"
+	"testing"
+)
+
+// Tests that Try converts the package's panics into errors.
+func TestTry(t *testing.T) {
+	if _, err := Try(func() Optimus { return New(1580030175, 1, 1163945558) }); !errors.Is(err, ErrNotPrime) {
+		t.Errorf("Expected ErrNotPrime. Got %v", err)
+	}
+
+	if _, err := Try(func() Optimus { return NewCalculated(2, 1163945558) }); !errors.Is(err, ErrEvenPrime) || errors.Is(err, ErrNotPrime) {
+		t.Errorf("Expected ErrEvenPrime. Got %v", err)
+	}
+
+	if _, err := Try(func() Optimus { return New(1, 1, 0) }); !errors.Is(err, ErrIdentityTransform) {
+		t.Errorf("Expected ErrIdentityTransform. Got %v", err)
+	}
+
+	o, err := Try(func() Optimus { return NewCalculated(1580030173, 1163945558) })
+	if err != nil || o != NewCalculated(1580030173, 1163945558) {
+		t.Errorf("Expected a valid Optimus. Got %v (%v)", o, err)
+	}
+}
+
+// Tests that panics from outside the package are not recovered.
+func TestTryForeignPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r != "foreign" {
+			t.Errorf("Expected the foreign panic to propagate. Got %v", r)
+		}
+	}()
+	Try(func() Optimus { panic("foreign") })
+	t.Errorf("Expected a panic")
+}
@@ -0,0 +1,22 @@
+//go:build go1.18
+// +build go1.18
+
+package optimus
+
+// ID is a uint64 id tagged with the phantom type K (eg. ID[User]) so that
+// ids of different types can not be mixed up by accident. ID[User] and
+// ID[Order] are distinct types: assigning one to the other, or passing one
+// where the other is expected, does not compile. An explicit conversion is
+// still allowed since both are uint64 underneath.
+type ID[K any] uint64
+
+// Encodes the typed id n.
+func EncodeTyped[K any](o Optimus, n ID[K]) uint64 {
+	return o.Encode(uint64(n))
+}
+
+// Decodes n into an id of type K. K can not be inferred so it must be given
+// explicitly, eg. DecodeTyped[User](o, n).
+func DecodeTyped[K any](o Optimus, n uint64) ID[K] {
+	return ID[K](o.Decode(n))
+}
@@ -0,0 +1,40 @@
+//go:build go1.18
+// +build go1.18
+
+package optimus
+
+import (
+	"testing"
+)
+
+type typedUser struct{}
+type typedOrder struct{}
+
+// Tests that typed ids round-trip and keep their type.
+//
+// Mixing types is a compile error, so it can not be tested at runtime:
+//
+//	var user ID[typedUser] = 15
+//	var order ID[typedOrder] = user // cannot use user (variable of uint64 type ID[typedUser]) as ID[typedOrder] value in variable declaration
+func TestTyped(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint64{0, 15, MAX_INT} {
+		user := ID[typedUser](value)
+		encoded := EncodeTyped(o, user)
+		if encoded != o.Encode(value) {
+			t.Errorf("%d: Expected %d. Got %d", value, o.Encode(value), encoded)
+		}
+
+		var decoded ID[typedUser] = DecodeTyped[typedUser](o, encoded)
+		if decoded != user {
+			t.Errorf("%d: %d -> %d - FAILED", value, encoded, decoded)
+		}
+	}
+
+	//The same id of different types encodes identically; only the static
+	//type differs
+	if EncodeTyped(o, ID[typedUser](15)) != EncodeTyped(o, ID[typedOrder](15)) {
+		t.Errorf("Expected the type not to affect the encoding")
+	}
+}
@@ -0,0 +1,28 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Encodes n like Encode for callers holding a uint. On 64-bit platforms
+// this never fails. On 32-bit platforms the encoded value usually needs
+// more than 32 bits, in which case an error is returned rather than a
+// truncated value. Use Optimus32 for 32-bit ids instead.
+func (this Optimus) EncodeUint(n uint) (uint, error) {
+	return toUint(this.Encode(uint64(n)))
+}
+
+// Decodes n like Decode for callers holding a uint. Returns an error if the
+// decoded value does not fit in a uint. See EncodeUint.
+func (this Optimus) DecodeUint(n uint) (uint, error) {
+	return toUint(this.Decode(uint64(n)))
+}
+
+// Converts n to a uint. Returns an error if it does not fit.
+func toUint(n uint64) (uint, error) {
+	if uint64(uint(n)) != n {
+		return 0, jsonerror.New(12, "Out of domain", fmt.Sprintf("%d does not fit in a uint on this platform", n))
+	}
+	return uint(n), nil
+}
@@ -0,0 +1,22 @@
+//go:build 386 || arm || mips || mipsle
+// +build 386 arm mips mipsle
+
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that EncodeUint reports an error instead of truncating when uint
+// is 32 bits wide.
+func TestEncodeUint32Bit(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	//15 encodes to a value above 2^32
+	if o.Encode(15) <= 1<<32-1 {
+		t.Fatalf("Expected Encode(15) to exceed 32 bits")
+	}
+	if _, err := o.EncodeUint(15); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !386 && !arm && !mips && !mipsle
+// +build !386,!arm,!mips,!mipsle
+
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that EncodeUint never fails when uint is 64 bits wide.
+func TestEncodeUint64Bit(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint{0, 15, 1 << 40, 1<<64 - 1} {
+		encoded, err := o.EncodeUint(value)
+		if err != nil {
+			t.Fatalf("%d - FAILED: %v", value, err)
+		}
+
+		decoded, err := o.DecodeUint(encoded)
+		if err != nil || decoded != value {
+			t.Errorf("%d: %d -> %d (%v) - FAILED", value, encoded, decoded, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that EncodeUint either agrees with Encode or reports an error,
+// whatever the platform width.
+func TestEncodeUint(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, value := range []uint{0, 1, 15, ^uint(0)} {
+		encoded, err := o.EncodeUint(value)
+		if err != nil {
+			if uint64(uint(o.Encode(uint64(value)))) == o.Encode(uint64(value)) {
+				t.Errorf("%d: Unexpected error %v", value, err)
+			}
+			continue
+		}
+
+		if uint64(encoded) != o.Encode(uint64(value)) {
+			t.Errorf("%d: Expected %d. Got %d", value, o.Encode(uint64(value)), encoded)
+		}
+
+		decoded, err := o.DecodeUint(encoded)
+		if err != nil || decoded != value {
+			t.Errorf("%d: %d -> %d (%v) - FAILED", value, encoded, decoded, err)
+		}
+	}
+}
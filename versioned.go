@@ -0,0 +1,78 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+)
+
+// Number of Base62 characters used to encode the version prefix.
+// Two characters can represent every byte value (62*62 > 255).
+const VERSION_PREFIX_LEN = 2
+
+// Encodes n and returns the Base62 representation prefixed with version.
+// Use a different version each time the seed is rotated so that tokens
+// generated with old seeds can be told apart from new ones.
+func (this Optimus) EncodeVersioned(version byte, n uint64) string {
+	return string([]byte{BASE62_ALPHABET[version/62], BASE62_ALPHABET[version%62]}) + base62Encode(this.Encode(n))
+}
+
+// Extracts the version prefix from a string produced by EncodeVersioned
+// and decodes the remainder. It is up to the caller to ensure that the
+// Optimus matches the returned version. See MultiDecoder.
+func (this Optimus) DecodeVersioned(s string) (version byte, n uint64, err error) {
+	version, encoded, err := splitVersioned(s)
+	if err != nil {
+		return 0, 0, err
+	}
+	return version, this.Decode(encoded), nil
+}
+
+// Splits a versioned string into its version and the encoded number.
+func splitVersioned(s string) (byte, uint64, error) {
+	if len(s) <= VERSION_PREFIX_LEN {
+		return 0, 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q is too short to contain a version prefix", s))
+	}
+
+	hi, lo := base62Digit(s[0]), base62Digit(s[1])
+	if hi < 0 || lo < 0 || hi*62+lo > 255 {
+		return 0, 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q has an invalid version prefix", s))
+	}
+
+	encoded, err := base62Decode(s[VERSION_PREFIX_LEN:])
+	if err != nil {
+		return 0, 0, err
+	}
+	return byte(hi*62 + lo), encoded, nil
+}
+
+// MultiDecoder routes strings produced by EncodeVersioned to the Optimus
+// registered for their version. This allows tokens generated with older
+// seeds to be decoded after the seed has been rotated.
+type MultiDecoder struct {
+	seeds map[byte]Optimus
+}
+
+// Returns a MultiDecoder which decodes each version using the associated
+// Optimus. The map is copied.
+func NewMultiDecoder(seeds map[byte]Optimus) MultiDecoder {
+	m := make(map[byte]Optimus, len(seeds))
+	for version, o := range seeds {
+		m[version] = o
+	}
+	return MultiDecoder{m}
+}
+
+// Decodes a string produced by EncodeVersioned using the Optimus registered
+// for its version. Returns an error if the version is unknown.
+func (this MultiDecoder) Decode(s string) (version byte, n uint64, err error) {
+	version, encoded, err := splitVersioned(s)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	o, ok := this.seeds[version]
+	if !ok {
+		return version, 0, jsonerror.New(5, "Unknown version", fmt.Sprintf("No Optimus registered for version %d", version))
+	}
+	return version, o.Decode(encoded), nil
+}
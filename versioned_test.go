@@ -0,0 +1,49 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that versioned strings round-trip for several versions and that
+// the MultiDecoder routes each version to the right seed.
+func TestEncodeVersioned(t *testing.T) {
+	seeds := map[byte]Optimus{
+		0:   NewCalculated(1580030173, 1163945558),
+		1:   NewCalculated(2123809381, 1198752319),
+		62:  NewCalculated(1500450271, 846103495),
+		255: NewCalculated(1580030173, 13),
+	}
+	m := NewMultiDecoder(seeds)
+
+	for version, o := range seeds {
+		for _, value := range []uint64{0, 15, 1103647397, MAX_INT} {
+			s := o.EncodeVersioned(version, value)
+
+			v, n, err := o.DecodeVersioned(s)
+			if err != nil || v != version || n != value {
+				t.Errorf("%d/%d: %s -> %d/%d (%v) - FAILED", version, value, s, v, n, err)
+			}
+
+			v, n, err = m.Decode(s)
+			if err != nil || v != version || n != value {
+				t.Errorf("MultiDecoder %d/%d: %s -> %d/%d (%v) - FAILED", version, value, s, v, n, err)
+			}
+		}
+	}
+}
+
+// Tests that unknown and malformed version prefixes are rejected.
+func TestDecodeVersionedInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	m := NewMultiDecoder(map[byte]Optimus{1: o})
+
+	if _, _, err := m.Decode(o.EncodeVersioned(2, 15)); err == nil {
+		t.Errorf("Expected unknown version 2 to be rejected")
+	}
+
+	for _, s := range []string{"", "01", "-1abc", "zzabc", "4Iabc"} { //4I = 256
+		if _, _, err := o.DecodeVersioned(s); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package optimus
+
+import (
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"strings"
+)
+
+// Separates the words of a string produced by EncodeWords.
+const WORD_SEPARATOR = "-"
+
+// Encodes n and returns it written in base len(wordlist) using the words of
+// wordlist as digits, joined by WORD_SEPARATOR, eg. "tiger-maple-river".
+// Returns an error if the wordlist is invalid. See ValidateWordlist.
+func (this Optimus) EncodeWords(n uint64, wordlist []string) (string, error) {
+	if err := ValidateWordlist(wordlist); err != nil {
+		return "", err
+	}
+
+	base := uint64(len(wordlist))
+	n = this.Encode(n)
+
+	words := []string{wordlist[n%base]}
+	for n /= base; n > 0; n /= base {
+		words = append(words, wordlist[n%base])
+	}
+
+	for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+		words[i], words[j] = words[j], words[i]
+	}
+	return strings.Join(words, WORD_SEPARATOR), nil
+}
+
+// Decodes a string produced by EncodeWords with the same wordlist. Words
+// are matched exactly, including case.
+func (this Optimus) DecodeWords(s string, wordlist []string) (uint64, error) {
+	if err := ValidateWordlist(wordlist); err != nil {
+		return 0, err
+	}
+	if s == "" {
+		return 0, jsonerror.New(4, "Invalid encoded string", "String is empty")
+	}
+
+	values := make(map[string]uint64, len(wordlist))
+	for i, word := range wordlist {
+		values[word] = uint64(i)
+	}
+	base := uint64(len(wordlist))
+
+	var n uint64
+	for i, word := range strings.Split(s, WORD_SEPARATOR) {
+		d, ok := values[word]
+		if !ok {
+			return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("Unknown word %q at position %d", word, i))
+		}
+		if n > (MAX_INT-d)/base {
+			return 0, jsonerror.New(4, "Invalid encoded string", fmt.Sprintf("%q overflows uint64", s))
+		}
+		n = n*base + d
+	}
+	return this.Decode(n), nil
+}
+
+// Returns an error if wordlist has fewer than 2 words, contains duplicates
+// or contains a word which is empty or includes WORD_SEPARATOR.
+func ValidateWordlist(wordlist []string) error {
+	if len(wordlist) < 2 {
+		return jsonerror.New(11, "Invalid alphabet", fmt.Sprintf("Wordlist must contain at least 2 words. Got %d", len(wordlist)))
+	}
+
+	seen := make(map[string]bool, len(wordlist))
+	for i, word := range wordlist {
+		if word == "" || strings.Contains(word, WORD_SEPARATOR) {
+			return jsonerror.New(11, "Invalid alphabet", fmt.Sprintf("Word %d (%q) must be non-empty and not contain %q", i, word, WORD_SEPARATOR))
+		}
+		if seen[word] {
+			return jsonerror.New(11, "Invalid alphabet", fmt.Sprintf("Wordlist contains %q more than once", word))
+		}
+		seen[word] = true
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package optimus
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests that values round-trip through a small wordlist.
+func TestEncodeWords(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+	wordlist := []string{"tiger", "maple", "river", "stone", "cloud", "amber", "frost", "lemon"}
+
+	for _, value := range []uint64{0, 1, 15, 1103647397, MAX_INT} {
+		s, err := o.EncodeWords(value, wordlist)
+		if err != nil {
+			t.Fatalf("%d: %v", value, err)
+		}
+
+		//8 words encode 3 bits each
+		if words := strings.Split(s, WORD_SEPARATOR); len(words) > 22 {
+			t.Errorf("%d: Expected at most 22 words. Got %d", value, len(words))
+		}
+
+		n, err := o.DecodeWords(s, wordlist)
+		if err != nil || n != value {
+			t.Errorf("%d: %s -> %d (%v) - FAILED", value, s, n, err)
+		}
+	}
+
+	//The encoding of 0 is the random number, 65 is 101 in base 8
+	if s, _ := NewCalculated(1580030173, 65).EncodeWords(0, wordlist); s != "maple-tiger-maple" {
+		t.Errorf("Expected maple-tiger-maple. Got %s", s)
+	}
+}
+
+// Tests that invalid wordlists and strings are rejected.
+func TestEncodeWordsInvalid(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	for _, wordlist := range [][]string{
+		nil,
+		{"tiger"},
+		{"tiger", "maple", "tiger"},
+		{"tiger", ""},
+		{"tiger", "sea-lion"},
+	} {
+		if _, err := o.EncodeWords(15, wordlist); err == nil {
+			t.Errorf("Expected %q to be rejected by EncodeWords", wordlist)
+		}
+		if _, err := o.DecodeWords("tiger", wordlist); err == nil {
+			t.Errorf("Expected %q to be rejected by DecodeWords", wordlist)
+		}
+	}
+
+	wordlist := []string{"tiger", "maple"}
+	for _, s := range []string{"", "tiger-", "tiger-lion", "Tiger", strings.Repeat("maple-", 64) + "maple"} {
+		if _, err := o.DecodeWords(s, wordlist); err == nil {
+			t.Errorf("Expected %q to be rejected", s)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package optimus
+
+import (
+	"github.com/pjebs/jsonerror"
+)
+
+// Returned by EncodeNonZero and DecodeNonZero for the zero id.
+var ErrZeroID = jsonerror.New(28, "Zero id", "0 is reserved for \"no id\"")
+
+// Encodes n like Encode but returns ErrZeroID if n is 0, for systems which
+// use 0 to mean "no id".
+func (this Optimus) EncodeNonZero(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, ErrZeroID
+	}
+	return this.Encode(n), nil
+}
+
+// Decodes n like Decode but returns ErrZeroID if n decodes to 0, ie. if n
+// is the random number.
+func (this Optimus) DecodeNonZero(n uint64) (uint64, error) {
+	decoded := this.Decode(n)
+	if decoded == 0 {
+		return 0, ErrZeroID
+	}
+	return decoded, nil
+}
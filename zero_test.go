@@ -0,0 +1,41 @@
+package optimus
+
+import (
+	"testing"
+)
+
+// Tests that 0 encodes to the random number by default.
+func TestZeroID(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	if got := o.Encode(0); got != 1163945558 {
+		t.Errorf("Expected 0 to encode to 1163945558. Got %d", got)
+	}
+	if got := o.Decode(1163945558); got != 0 {
+		t.Errorf("Expected 1163945558 to decode to 0. Got %d", got)
+	}
+}
+
+// Tests that EncodeNonZero and DecodeNonZero reject the zero id.
+func TestZeroIDRejected(t *testing.T) {
+	o := NewCalculated(1580030173, 1163945558)
+
+	if _, err := o.EncodeNonZero(0); err != ErrZeroID {
+		t.Errorf("Expected ErrZeroID. Got %v", err)
+	}
+	if _, err := o.DecodeNonZero(o.Encode(0)); err != ErrZeroID {
+		t.Errorf("Expected ErrZeroID. Got %v", err)
+	}
+
+	for _, value := range []uint64{1, 15, MAX_INT} {
+		encoded, err := o.EncodeNonZero(value)
+		if err != nil || encoded != o.Encode(value) {
+			t.Errorf("%d: Expected %d. Got %d (%v)", value, o.Encode(value), encoded, err)
+		}
+
+		decoded, err := o.DecodeNonZero(encoded)
+		if err != nil || decoded != value {
+			t.Errorf("%d: %d -> %d (%v) - FAILED", value, encoded, decoded, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package optimus
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/pjebs/jsonerror"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+)
+
+// ZipFilePrimeSource reads candidate primes from a local copy of one of the
+// zip files from http://primes.utm.edu/lists/small/millions/, eg. in
+// air-gapped environments.
+// WARNING: Potentially Insecure. See GenerateSeed.
+type ZipFilePrimeSource struct {
+	Path string
+}
+
+// Returns the numbers found around a random position within the zip file.
+func (this ZipFilePrimeSource) Primes() ([]uint64, error) {
+	f, err := os.Open(this.Path)
+	if err != nil {
+		return nil, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+	defer f.Close()
+
+	primes, _, _, err := readPrimesWindow(f)
+	return primes, err
+}
+
+// Generates a valid Optimus struct like GenerateSeed but using a local copy
+// of one of the zip files instead of downloading it. This is available in
+// builds using the optimus_no_network tag. Like GenerateSeed, the primes are
+// always below the default MinSecurePrime.
+func GenerateSeedFromZipFile(path string) (*Optimus, error) {
+	return GenerateSeedFrom(ZipFilePrimeSource{path})
+}
+
+// Streams the primes zip file in r and returns the numbers found in a small
+// window at a random position after the header, along with the window's
+// bounds.
+func readPrimesWindow(r io.Reader) ([]uint64, uint64, uint64, error) {
+	//Stream the zip entry without buffering the archive
+	entry, size, err := openZipEntry(r)
+	if err != nil {
+		return nil, 0, 0, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+	defer entry.Close()
+
+	var src io.Reader = entry
+	if size < 0 {
+		//The size is only recorded after the data so the entry must be buffered
+		b, err := ioutil.ReadAll(entry)
+		if err != nil {
+			return nil, 0, 0, jsonerror.New(1, "Could not generate seed", err.Error())
+		}
+		src = bytes.NewReader(b)
+		size = int64(len(b))
+	}
+
+	//Randomly pick a character position
+	start := 67 // Each zip file has an introductory header which is not relevant until the 67th character
+	end := size
+
+	if end <= int64(start) {
+		return nil, 0, 0, jsonerror.New(1, "Could not generate seed", "Zip file contains no primes")
+	}
+
+	b_end := *big.NewInt(end - int64(start))
+	n, err := rand.Int(randReader, &b_end)
+	if err != nil {
+		return nil, 0, 0, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+	randomPosition := n.Uint64() + uint64(start)
+
+	//Clamp the window to [start, end) without underflowing
+	min := uint64(start)
+	if randomPosition > min+9 {
+		min = randomPosition - 9
+	}
+
+	max := randomPosition + 9
+	if max > uint64(end) {
+		max = uint64(end)
+	}
+
+	//Skip to the window without keeping what comes before it
+	if _, err := io.CopyN(ioutil.Discard, src, int64(min)); err != nil {
+		return nil, 0, 0, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+
+	b := make([]byte, max-min)
+	if _, err := io.ReadFull(src, b); err != nil {
+		return nil, 0, 0, jsonerror.New(1, "Could not generate seed", err.Error())
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b)) //Input
+	scanner.Split(bufio.ScanWords)
+
+	var selectedNumbers []uint64
+	for scanner.Scan() {
+		//Skip anything which is not a number rather than returning 0
+		p, err := strconv.ParseUint(scanner.Text(), 10, 64)
+		if err != nil {
+			continue
+		}
+		selectedNumbers = append(selectedNumbers, p)
+	}
+
+	return selectedNumbers, min, max, nil
+}
+
+// Reads the local file header of the first entry of the zip archive in r and
+// returns a reader for its uncompressed contents along with its uncompressed
+// size. Unlike archive/zip, the archive does not need to be buffered. The
+// size is -1 if the archive only records it after the data.
+func openZipEntry(r io.Reader) (io.ReadCloser, int64, error) {
+	var header [30]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, 0, err
+	}
+
+	if binary.LittleEndian.Uint32(header[0:]) != 0x04034b50 {
+		return nil, 0, errors.New("zip: not a valid zip file")
+	}
+
+	flags := binary.LittleEndian.Uint16(header[6:])
+	method := binary.LittleEndian.Uint16(header[8:])
+	compressedSize := binary.LittleEndian.Uint32(header[18:])
+	size := int64(binary.LittleEndian.Uint32(header[22:]))
+	nameLen := binary.LittleEndian.Uint16(header[26:])
+	extraLen := binary.LittleEndian.Uint16(header[28:])
+
+	//Bit 3 means the sizes are in a data descriptor after the data.
+	//0xFFFFFFFF means the sizes are in the zip64 extra field.
+	if flags&0x8 != 0 || size == 0xFFFFFFFF {
+		size = -1
+	}
+
+	if _, err := io.CopyN(ioutil.Discard, r, int64(nameLen)+int64(extraLen)); err != nil {
+		return nil, 0, err
+	}
+
+	switch method {
+	case 0: //Stored
+		if flags&0x8 != 0 {
+			return nil, 0, errors.New("zip: stored entry without size")
+		}
+		return ioutil.NopCloser(io.LimitReader(r, int64(compressedSize))), size, nil
+	case 8: //Deflated
+		return flate.NewReader(r), size, nil
+	}
+	return nil, 0, fmt.Errorf("zip: unsupported compression method %d", method)
+}
@@ -0,0 +1,145 @@
+package optimus
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Header with the same length as the one found in the primes.utm.edu files.
+const fakePrimesHeader = "                  The First 1,000,000 Primes (from primes.utm.edu)\n"
+
+// Returns a zip archive containing a single file with the given contents,
+// in the same format as the primes.utm.edu files.
+func fakePrimesZip(t *testing.T, contents string) []byte {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	f, err := w.Create("primes.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(contents))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Returns a zip archive like fakePrimesZip but with the sizes recorded in the
+// local file header, as in the primes.utm.edu files, so that it can be
+// streamed.
+func fakePrimesZipSized(t *testing.T, contents string) []byte {
+	compressed := new(bytes.Buffer)
+	fw, _ := flate.NewWriter(compressed, flate.BestSpeed)
+	fw.Write([]byte(contents))
+	fw.Close()
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	f, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "primes.txt",
+		Method:             zip.Deflate,
+		CRC32:              crc32.ChecksumIEEE([]byte(contents)),
+		CompressedSize64:   uint64(compressed.Len()),
+		UncompressedSize64: uint64(len(contents)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(compressed.Bytes())
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Tests that a seed is generated from the local fixture zip file.
+func TestGenerateSeedFromZipFile(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		o, err := GenerateSeedFromZipFile("testdata/primes.zip")
+		if err != nil {
+			t.Fatalf("Try %d - FAILED: %v", i, err)
+		}
+		if o.Prime() != 7919 || o.Prime()*o.ModInverse() != 1 {
+			t.Errorf("Expected prime 7919 with a valid modInverse. Got %d and %d", o.Prime(), o.ModInverse())
+		}
+	}
+}
+
+// Tests that missing and malformed files are rejected.
+func TestGenerateSeedFromZipFileInvalid(t *testing.T) {
+	for _, path := range []string{"testdata/missing.zip", "zipprimes_test.go"} {
+		if _, err := GenerateSeedFromZipFile(path); err == nil {
+			t.Errorf("Expected %s to be rejected", path)
+		}
+	}
+}
+
+// Tests that a failing randReader returns an error instead of panicking.
+func TestGenerateSeedFromZipFileFailingRand(t *testing.T) {
+	defer func(original io.Reader) { randReader = original }(randReader)
+	randReader = bytes.NewReader(nil)
+
+	if _, err := GenerateSeedFromZipFile("testdata/primes.zip"); err == nil {
+		t.Errorf("Expected an error - FAILED")
+	}
+}
+
+// Tests that tiny and truncated zip contents return an error or a small
+// window instead of panicking on the window bounds.
+func TestReadPrimesWindowSmall(t *testing.T) {
+	for name, contents := range map[string]string{
+		"empty":        "",
+		"short header": fakePrimesHeader[:20],
+		"header only":  fakePrimesHeader,
+	} {
+		for _, body := range [][]byte{fakePrimesZip(t, contents), fakePrimesZipSized(t, contents)} {
+			if primes, _, _, err := readPrimesWindow(bytes.NewReader(body)); err == nil {
+				t.Errorf("%s: Expected an error. Got %v", name, primes)
+			}
+		}
+	}
+
+	//A single digit after the header is the smallest valid window
+	for i := 0; i < 10; i++ {
+		for _, body := range [][]byte{fakePrimesZip(t, fakePrimesHeader+"7"), fakePrimesZipSized(t, fakePrimesHeader+"7")} {
+			primes, min, max, err := readPrimesWindow(bytes.NewReader(body))
+			if err != nil || len(primes) != 1 || primes[0] != 7 || min != 67 || max != 68 {
+				t.Errorf("Expected [7] in [67, 68). Got %v in [%d, %d) (%v)", primes, min, max, err)
+			}
+		}
+	}
+
+	//The header claims more data than the archive contains
+	contents := fakePrimesHeader + strings.Repeat("     7919", 2)
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	f, err := w.CreateRaw(&zip.FileHeader{
+		Name:               "primes.txt",
+		Method:             zip.Store,
+		CompressedSize64:   uint64(len(contents)),
+		UncompressedSize64: uint64(len(contents)) + 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte(contents))
+	w.Close()
+
+	for i := 0; i < 10; i++ {
+		primes, min, max, err := readPrimesWindow(bytes.NewReader(buf.Bytes()))
+		if err == nil && (max > uint64(len(contents)) || min > max) {
+			t.Errorf("Unexpected window [%d, %d) with %v", min, max, primes)
+		}
+	}
+
+	//Words which are not numbers are skipped rather than returned as 0
+	primes, _, _, err := readPrimesWindow(bytes.NewReader(fakePrimesZip(t, fakePrimesHeader+"x")))
+	if err != nil || len(primes) != 0 {
+		t.Errorf("Expected no candidates. Got %v (%v)", primes, err)
+	}
+}